@@ -2,7 +2,12 @@ package main
 
 import (
 	"SolverAPI/internal/app"
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -15,9 +20,29 @@ func main() {
 		log.Fatalf("Failed to setup dependencies: %v", err)
 	}
 
-	log.Printf("Starting server on port %s", server.Config.HTTPServer.Port)
-	if err := server.Start(); err != nil {
-		log.Fatal("Server error: ", err)
+	// Останавливаемся по Ctrl+C или SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on port %s", server.Config.HTTPServer.Port)
+		errCh <- server.Start(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			log.Fatal("Server error: ", err)
+		}
+	case <-ctx.Done():
+		log.Println("Shutting down server")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown error: %v", err)
 	}
 }
 
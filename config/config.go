@@ -25,10 +25,66 @@ type Config struct {
 	HTTPServer  HTTPServer
 	Codewars    CodewarsConfig
 	Database    DatabaseConfig
+	Sync        SyncConfig
+	Admin       AdminConfig
 }
 
 type CodewarsConfig struct {
-	APIURL string `env:"CODEWARS_API_URL" envDefault:"https://www.codewars.com/api/v1"`
+	APIURL    string        `env:"CODEWARS_API_URL" envDefault:"https://www.codewars.com/api/v1"`
+	SearchURL string        `env:"CODEWARS_SEARCH_URL" envDefault:"https://www.codewars.com/kata/search"`
+	UserAgent string        `env:"CODEWARS_USER_AGENT" envDefault:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"`
+	Timeout   time.Duration `env:"CODEWARS_TIMEOUT" envDefault:"10s"`
+
+	// Повторы запросов при 5xx, 429 и сетевых ошибках
+	RetryMaxAttempts int           `env:"CODEWARS_RETRY_MAX_ATTEMPTS" envDefault:"3"`
+	RetryBaseDelay   time.Duration `env:"CODEWARS_RETRY_BASE_DELAY" envDefault:"200ms"`
+	RetryMaxDelay    time.Duration `env:"CODEWARS_RETRY_MAX_DELAY" envDefault:"5s"`
+
+	// Общий лимит исходящих запросов (token bucket), 0 - без ограничения
+	RateLimit float64 `env:"CODEWARS_RATE_LIMIT" envDefault:"5"`
+	RateBurst int     `env:"CODEWARS_RATE_BURST" envDefault:"10"`
+
+	// Кэш ответов (LRU), 0 - кэш отключен. TTL 0 отключает кэш для эндпоинта
+	CacheSize          int           `env:"CODEWARS_CACHE_SIZE" envDefault:"1000"`
+	CacheUserTTL       time.Duration `env:"CODEWARS_CACHE_USER_TTL" envDefault:"1m"`
+	CacheKataTTL       time.Duration `env:"CODEWARS_CACHE_KATA_TTL" envDefault:"24h"`
+	CacheChallengesTTL time.Duration `env:"CODEWARS_CACHE_CHALLENGES_TTL" envDefault:"5m"`
+
+	// Circuit breaker: после BreakerFailures сбоев подряд запросы
+	// отклоняются сразу в течение BreakerCoolDown. 0 - breaker отключен
+	BreakerFailures int           `env:"CODEWARS_BREAKER_FAILURES" envDefault:"5"`
+	BreakerCoolDown time.Duration `env:"CODEWARS_BREAKER_COOLDOWN" envDefault:"30s"`
+
+	// Период фонового обновления буфера ID задач
+	BufferInterval time.Duration `env:"CODEWARS_BUFFER_INTERVAL" envDefault:"1h"`
+
+	// Режим транспорта: live - запросы к Codewars, record - запросы с записью
+	// ответов в FixturesDir, replay - ответы только из FixturesDir
+	Mode        string `env:"CODEWARS_MODE" envDefault:"live"`
+	FixturesDir string `env:"CODEWARS_FIXTURES_DIR" envDefault:"testdata/codewars"`
+}
+
+// SyncConfig - синхронизация пользователей с Codewars
+type SyncConfig struct {
+	// Фоновая синхронизация отслеживаемых пользователей, Interval 0 - отключена
+	Interval    time.Duration `env:"SYNC_INTERVAL" envDefault:"1h"`
+	Concurrency int           `env:"SYNC_CONCURRENCY" envDefault:"4"`
+	Jitter      time.Duration `env:"SYNC_JITTER" envDefault:"30s"`
+
+	// Сколько после синхронизации GET /users/:username отдает пользователя из БД,
+	// 0 - всегда запрашивать Codewars
+	UserFreshness time.Duration `env:"SYNC_USER_FRESHNESS" envDefault:"5m"`
+
+	// Добавлять в отслеживаемые уже известных соклановцев синхронизированного пользователя.
+	// Соклановцы одного клана добавляются не чаще раза в ClanMinAge
+	ClanAutoTrack bool          `env:"SYNC_CLAN_AUTO_TRACK" envDefault:"false"`
+	ClanMinAge    time.Duration `env:"SYNC_CLAN_MIN_AGE" envDefault:"1h"`
+}
+
+// AdminConfig - доступ к административным эндпоинтам /admin
+type AdminConfig struct {
+	// Токен для заголовка Authorization: Bearer <token>. Пустой - /admin отключен
+	Token string `env:"ADMIN_TOKEN"`
 }
 
 type DatabaseConfig struct {
@@ -51,6 +107,11 @@ func Load() (*Config, error) {
 	if cfg.HTTPServer.Port == "" {
 		return nil, errors.New("SERVER_PORT не может быть пустым")
 	}
+	switch cfg.Codewars.Mode {
+	case "live", "record", "replay":
+	default:
+		return nil, fmt.Errorf("CODEWARS_MODE должен быть live, record или replay, получено %q", cfg.Codewars.Mode)
+	}
 
 	return cfg, nil
 }
@@ -8,7 +8,9 @@ import (
 	"SolverAPI/pkg/codewars"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	_ "github.com/lib/pq" // Драйвер PostgreSQL
@@ -24,6 +26,18 @@ type Server struct {
 	Echo     *echo.Echo
 	Config   *config.Config
 	Codewars *codewars.Client
+
+	// Зависимости сервисов от Codewars. По умолчанию это Codewars,
+	// но до SetupDependencies их можно обернуть или заменить
+	CodewarsUsers  service.CodewarsUsers
+	CodewarsKatas  service.CodewarsKatas
+	CodewarsStats  handler.CodewarsStats
+	CodewarsCache  handler.CodewarsCache
+	CodewarsState  handler.CodewarsBreaker
+	CodewarsBuffer handler.KataBufferStats
+
+	// Фоновая синхронизация отслеживаемых пользователей, создается в SetupDependencies
+	Scheduler *service.SyncScheduler
 }
 
 func New() (*Server, error) {
@@ -37,29 +51,109 @@ func New() (*Server, error) {
 	e := cfg.HTTPServer.NewEcho()
 
 	// Инициализируем клиенты
-	cwClient := codewars.NewClient(cfg.Codewars.APIURL)
+	cwClient := codewars.NewClient(cfg.Codewars.APIURL,
+		codewars.WithSearchURL(cfg.Codewars.SearchURL),
+		codewars.WithUserAgent(cfg.Codewars.UserAgent),
+		codewars.WithTimeout(cfg.Codewars.Timeout),
+		codewars.WithRetryPolicy(codewars.RetryPolicy{
+			MaxAttempts: cfg.Codewars.RetryMaxAttempts,
+			BaseDelay:   cfg.Codewars.RetryBaseDelay,
+			MaxDelay:    cfg.Codewars.RetryMaxDelay,
+		}),
+		codewars.WithRateLimit(codewars.RateLimit{
+			Rate:  cfg.Codewars.RateLimit,
+			Burst: cfg.Codewars.RateBurst,
+		}),
+		codewars.WithCache(codewars.CacheConfig{
+			MaxEntries: cfg.Codewars.CacheSize,
+			TTL: map[codewars.Endpoint]time.Duration{
+				codewars.EndpointUser:      cfg.Codewars.CacheUserTTL,
+				codewars.EndpointKata:      cfg.Codewars.CacheKataTTL,
+				codewars.EndpointCompleted: cfg.Codewars.CacheChallengesTTL,
+				codewars.EndpointAuthored:  cfg.Codewars.CacheChallengesTTL,
+			},
+		}),
+		codewars.WithCircuitBreaker(codewars.BreakerConfig{
+			FailureThreshold: cfg.Codewars.BreakerFailures,
+			CoolDown:         cfg.Codewars.BreakerCoolDown,
+		}),
+		codewars.WithBufferInterval(cfg.Codewars.BufferInterval),
+		codewars.WithMode(codewars.Mode(cfg.Codewars.Mode), cfg.Codewars.FixturesDir),
+	)
 
 	return &Server{
-		Echo:     e,
-		Config:   cfg,
-		Codewars: cwClient,
+		Echo:           e,
+		Config:         cfg,
+		Codewars:       cwClient,
+		CodewarsUsers:  cwClient,
+		CodewarsKatas:  cwClient,
+		CodewarsStats:  cwClient,
+		CodewarsCache:  cwClient,
+		CodewarsState:  cwClient,
+		CodewarsBuffer: cwClient,
 	}, nil
 }
 
-func (s *Server) RegisterHandlers(userService *service.UserService, kataService *service.KataService) {
-	userHandler := handler.NewUserHandler(s.Codewars, userService)
+func (s *Server) RegisterHandlers(
+	userService *service.UserService,
+	kataService *service.KataService,
+	trackingService *service.TrackingService,
+	leaderboardService *service.LeaderboardService,
+) {
+	userHandler := handler.NewUserHandler(userService)
+	trackedHandler := handler.NewTrackedUserHandler(trackingService)
+	leaderboardHandler := handler.NewLeaderboardHandler(leaderboardService)
 	kataHandler := handler.NewKataHandler(kataService)
+	clanHandler := handler.NewClanHandler(userService)
+	bufferHandler := handler.NewKataBufferHandler(s.CodewarsBuffer)
 
 	//health-check
-	healthHandler := handler.NewHealthHandler()
+	healthHandler := handler.NewHealthHandler(s.CodewarsState)
+	metricsHandler := handler.NewMetricsHandler(s.CodewarsStats)
+	adminHandler := handler.NewAdminHandler(s.CodewarsCache)
 
 	s.Echo.GET("/health", healthHandler.Check)
+	s.Echo.GET("/metrics", metricsHandler.Get)
+	s.Echo.GET("/users", userHandler.ListUsers)
 	s.Echo.GET("/users/:username", userHandler.GetUser)
+	s.Echo.GET("/users/:username/completed", userHandler.GetCompletedChallenges)
+	s.Echo.GET("/users/:username/authored", userHandler.GetAuthoredChallenges)
+	s.Echo.GET("/users/:username/history", userHandler.GetHistory)
+	s.Echo.GET("/clans/:clan/users", clanHandler.GetClanUsers)
+	s.Echo.GET("/tracked-users", trackedHandler.List)
+	s.Echo.POST("/tracked-users/:username", trackedHandler.Track)
+	s.Echo.DELETE("/tracked-users/:username", trackedHandler.Untrack)
+	s.Echo.GET("/leaderboard", leaderboardHandler.Get)
 	s.Echo.GET("/katas/random", kataHandler.GetRandomKata) // Новый эндпоинт
+	s.Echo.GET("/katas/search", kataHandler.SearchKatas)
+	s.Echo.GET("/katas/buffer", bufferHandler.GetBuffer)
+
+	admin := s.Echo.Group("/admin", handler.AdminAuth(s.Config.Admin.Token))
+	admin.DELETE("/cache", adminHandler.PurgeCache)
+}
+
+// Start запускает фоновые задачи и HTTP-сервер. Блокируется до остановки сервера
+func (s *Server) Start(ctx context.Context) error {
+	s.Codewars.Start(ctx)
+	if s.Scheduler != nil {
+		s.Scheduler.Start(ctx)
+	}
+
+	err := s.Echo.Start(":" + s.Config.HTTPServer.Port)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s *Server) Start() error {
-	return s.Echo.Start(":" + s.Config.HTTPServer.Port)
+// Shutdown останавливает HTTP-сервер и фоновые задачи
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.Echo.Shutdown(ctx)
+	if s.Scheduler != nil {
+		s.Scheduler.Stop()
+	}
+	s.Codewars.Stop()
+	return err
 }
 
 func (s *Server) SetupDependencies() error {
@@ -83,13 +177,32 @@ func (s *Server) SetupDependencies() error {
 	// Инициализация репозиториев
 	userRepo := postgres.NewUserRepository(db)
 	kataRepo := postgres.NewKataRepository(db)
+	challengeRepo := postgres.NewCompletedChallengeRepository(db)
+	authoredRepo := postgres.NewAuthoredChallengeRepository(db)
+	snapshotRepo := postgres.NewUserSnapshotRepository(db)
+	trackedRepo := postgres.NewTrackedUserRepository(db)
+
+	// Буфер задач загружается из БД при старте и сохраняется при обновлении
+	s.Codewars.SetBufferStore(postgres.NewKataBufferRepository(db))
 
 	// Инициализация сервисов
-	userService := service.NewUserService(userRepo, s.Codewars)
-	kataService := service.NewKataService(kataRepo, s.Codewars)
+	userService := service.NewUserService(userRepo, challengeRepo, authoredRepo, snapshotRepo, s.CodewarsUsers)
+	kataService := service.NewKataService(kataRepo, s.CodewarsKatas)
+	userService.SetFreshness(s.Config.Sync.UserFreshness)
+	trackingService := service.NewTrackingService(trackedRepo, userService)
+	leaderboardService := service.NewLeaderboardService(snapshotRepo, trackedRepo)
+	if s.Config.Sync.ClanAutoTrack {
+		userService.EnableClanAutoTrack(trackedRepo, s.Config.Sync.ClanMinAge)
+	}
+
+	s.Scheduler = service.NewSyncScheduler(trackedRepo, userService, service.SchedulerConfig{
+		Interval:    s.Config.Sync.Interval,
+		Concurrency: s.Config.Sync.Concurrency,
+		Jitter:      s.Config.Sync.Jitter,
+	})
 
 	// Регистрация обработчиков
-	s.RegisterHandlers(userService, kataService) // Обновляем метод
+	s.RegisterHandlers(userService, kataService, trackingService, leaderboardService) // Обновляем метод
 	return nil
 }
 
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"SolverAPI/pkg/codewars"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CodewarsCache - управление кэшем ответов Codewars
+type CodewarsCache interface {
+	PurgeCache(endpoint codewars.Endpoint) int
+}
+
+type AdminHandler struct {
+	cache CodewarsCache
+}
+
+func NewAdminHandler(cache CodewarsCache) *AdminHandler {
+	return &AdminHandler{cache: cache}
+}
+
+// AdminAuth пропускает запрос только с заголовком Authorization: Bearer <token>.
+// Пустой token отключает административные эндпоинты
+func AdminAuth(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if token == "" {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"code":  codeForbidden,
+					"error": "admin API is disabled: ADMIN_TOKEN is not set",
+				})
+			}
+
+			got, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				c.Response().Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"code":  codeUnauthorized,
+					"error": "missing or invalid admin token",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// PurgeCache очищает кэш ответов Codewars.
+// ?endpoint=user|kata|kata_list|completed|authored|search ограничивает очистку
+// одним эндпоинтом, без него очищается весь кэш
+func (h *AdminHandler) PurgeCache(c echo.Context) error {
+	endpoint := codewars.Endpoint(c.QueryParam("endpoint"))
+	if endpoint != "" && !endpoint.Valid() {
+		return badRequest(c, fmt.Sprintf("unknown endpoint %q", endpoint))
+	}
+
+	purged := h.cache.PurgeCache(endpoint)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"endpoint": endpoint,
+		"purged":   purged,
+	})
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"SolverAPI/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ClanHandler struct {
+	userService *service.UserService
+}
+
+func NewClanHandler(userService *service.UserService) *ClanHandler {
+	return &ClanHandler{userService: userService}
+}
+
+// GetClanUsers возвращает известных пользователей клана: число участников,
+// суммарный хонор, средний ранг и список пользователей по убыванию хонора
+func (h *ClanHandler) GetClanUsers(c echo.Context) error {
+	clan, err := h.userService.GetClan(c.Request().Context(), c.Param("clan"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, clan)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"SolverAPI/internal/repository"
+	"SolverAPI/pkg/codewars"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Стабильные коды ошибок, на которые могут опираться клиенты API
+const (
+	codeInvalidRequest      = "invalid_request"
+	codeNotFound            = "not_found"
+	codeUnauthorized        = "unauthorized"
+	codeForbidden           = "forbidden"
+	codeRateLimited         = "rate_limited"
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeUpstreamBadResponse = "upstream_bad_response"
+	codeUpstreamTimeout     = "upstream_timeout"
+	codeCircuitOpen         = "upstream_circuit_open"
+	codeFixtureNotFound     = "fixture_not_found"
+	codeInternal            = "internal_error"
+)
+
+// respondError переводит ошибку сервиса в HTTP-ответ
+func respondError(c echo.Context, err error) error {
+	status, code := errorStatus(err)
+
+	if retryAfter := errorRetryAfter(err); retryAfter > 0 {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	return c.JSON(status, map[string]string{
+		"code":  code,
+		"error": err.Error(),
+	})
+}
+
+// badRequest отвечает 400 на некорректные параметры запроса
+func badRequest(c echo.Context, message string) error {
+	return c.JSON(http.StatusBadRequest, map[string]string{
+		"code":  codeInvalidRequest,
+		"error": message,
+	})
+}
+
+func errorStatus(err error) (int, string) {
+	var statusErr *codewars.StatusError
+	switch {
+	case errors.Is(err, codewars.ErrNotFound), errors.Is(err, repository.ErrUserNotFound),
+		errors.Is(err, repository.ErrClanNotFound), errors.Is(err, repository.ErrNotTracked):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, codewars.ErrRateLimited):
+		return http.StatusTooManyRequests, codeRateLimited
+	case errors.Is(err, codewars.ErrFixtureNotFound):
+		// Режим replay: для запроса нет записанного ответа Codewars
+		return http.StatusNotImplemented, codeFixtureNotFound
+	case errors.Is(err, codewars.ErrCircuitOpen):
+		return http.StatusServiceUnavailable, codeCircuitOpen
+	case errors.Is(err, codewars.ErrUnavailable):
+		return http.StatusBadGateway, codeUpstreamUnavailable
+	case errors.Is(err, codewars.ErrDecode), errors.As(err, &statusErr):
+		return http.StatusBadGateway, codeUpstreamBadResponse
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, codeUpstreamTimeout
+	default:
+		return http.StatusInternalServerError, codeInternal
+	}
+}
+
+func errorRetryAfter(err error) time.Duration {
+	var rateLimit *codewars.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return rateLimit.RetryAfter
+	}
+	var circuitOpen *codewars.CircuitOpenError
+	if errors.As(err, &circuitOpen) {
+		return circuitOpen.RetryAfter
+	}
+	return 0
+}
@@ -1,21 +1,41 @@
 package handler
 
 import (
+	"SolverAPI/pkg/codewars"
 	"net/http"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-type HealthHandler struct{}
+// CodewarsBreaker - состояние circuit breaker клиента Codewars
+type CodewarsBreaker interface {
+	BreakerStats() codewars.BreakerStats
+}
+
+type HealthHandler struct {
+	codewars CodewarsBreaker
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(cw CodewarsBreaker) *HealthHandler {
+	return &HealthHandler{codewars: cw}
 }
 
+// Check отвечает 200 и при открытом breaker: сервис работает,
+// но запросы к Codewars временно не выполняются
 func (h *HealthHandler) Check(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status": "OK",
+	breaker := h.codewars.BreakerStats()
+
+	status := "OK"
+	if breaker.State != codewars.BreakerClosed {
+		status = "DEGRADED"
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": status,
 		"time":   time.Now().UTC().Format(time.RFC3339),
+		"codewars": map[string]interface{}{
+			"circuit_breaker": breaker,
+		},
 	})
 }
@@ -1,12 +1,19 @@
 package handler
 
 import (
+	"SolverAPI/internal/model"
 	"SolverAPI/internal/service"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
+// maxSearchLimit ограничивает число задач в ответе /katas/search
+const maxSearchLimit = 300
+
 type KataHandler struct {
 	kataService *service.KataService
 }
@@ -15,20 +22,100 @@ func NewKataHandler(ks *service.KataService) *KataHandler {
 	return &KataHandler{kataService: ks}
 }
 
+// GetRandomKata поддерживает те же фильтры, что и SearchKatas,
+// например /katas/random?rank=6&language=go
 func (h *KataHandler) GetRandomKata(c echo.Context) error {
-	kata, err := h.kataService.GetRandomKata(c.Request().Context())
+	filter, err := parseKataFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error":   "Failed to get random kata",
-			"details": err.Error(),
-		})
-	}
-
-	// Возвращаем только необходимые данные
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":   kata.ID,
-		"name": kata.Name,
-		"url":  kata.URL,
-		"tags": kata.Tags,
-	})
+		return badRequest(c, err.Error())
+	}
+
+	kata, err := h.kataService.GetRandomKata(c.Request().Context(), filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	// Модель отдается целиком, как и пользователь в GetUser: поля задачи
+	// в тех же camelCase-ключах, что и у Codewars, плюс stale
+	if kata.Stale {
+		setStaleHeaders(c, kata.SyncedAt)
+	}
+
+	return c.JSON(http.StatusOK, kata)
+}
+
+// SearchKatas - поиск задач на Codewars.
+// Параметры: q, language, rank (6, 6kyu, 1dan; через запятую), tags (через запятую),
+// status (approved|beta), order_by, limit
+func (h *KataHandler) SearchKatas(c echo.Context) error {
+	filter, err := parseKataFilter(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	limit := 0
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			return badRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+		}
+	}
+
+	katas, err := h.kataService.SearchKatas(c.Request().Context(), filter, limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, katas)
+}
+
+func parseKataFilter(c echo.Context) (model.KataFilter, error) {
+	filter := model.KataFilter{
+		Query:    strings.TrimSpace(c.QueryParam("q")),
+		Language: strings.ToLower(strings.TrimSpace(c.QueryParam("language"))),
+		Tags:     splitList(c.QueryParam("tags")),
+		Status:   c.QueryParam("status"),
+		OrderBy:  c.QueryParam("order_by"),
+	}
+
+	if filter.Status != "" && filter.Status != "approved" && filter.Status != "beta" {
+		return filter, fmt.Errorf("status must be approved or beta")
+	}
+
+	for _, raw := range splitList(c.QueryParam("rank")) {
+		rank, err := parseRank(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.Ranks = append(filter.Ranks, rank)
+	}
+
+	return filter, nil
+}
+
+// parseRank переводит "6", "6kyu", "6 kyu" в -6, а "1dan" в 1.
+// Отрицательные числа принимаются как есть (формат Codewars)
+func parseRank(raw string) (int, error) {
+	s := strings.ToLower(strings.ReplaceAll(raw, " ", ""))
+	dan := strings.HasSuffix(s, "dan")
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "kyu"), "dan")
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n == 0 || n < -8 || n > 8 {
+		return 0, fmt.Errorf("invalid rank %q: use 1-8 kyu or 1-8 dan", raw)
+	}
+	if n < 0 || dan {
+		return n, nil
+	}
+	return -n, nil
+}
+
+func splitList(raw string) []string {
+	var result []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
 }
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"SolverAPI/pkg/codewars"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// KataBufferStats - состояние буфера ID задач
+type KataBufferStats interface {
+	BufferStats() []codewars.BufferStats
+}
+
+type KataBufferHandler struct {
+	buffer KataBufferStats
+}
+
+func NewKataBufferHandler(buffer KataBufferStats) *KataBufferHandler {
+	return &KataBufferHandler{buffer: buffer}
+}
+
+// GetBuffer возвращает размер и возраст буфера по каждому фильтру
+func (h *KataBufferHandler) GetBuffer(c echo.Context) error {
+	stats := h.buffer.BufferStats()
+
+	total := 0
+	for _, s := range stats {
+		total += s.Size
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total":   total,
+		"filters": stats,
+	})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/service"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService *service.LeaderboardService
+}
+
+func NewLeaderboardHandler(ls *service.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{leaderboardService: ls}
+}
+
+// Get возвращает рейтинг пользователей.
+// Параметры: metric (honor|rank|language|honor_gain|completions, по умолчанию honor_gain -
+// прирост за период; honor за все время почти не меняет порядок от периода к периоду),
+// language (для metric=language), period (week|month, по умолчанию month - текущий
+// календарный период в UTC), from и to (YYYY-MM-DD или RFC3339, вместо period),
+// scope (tracked|all, по умолчанию tracked).
+// Изменение места считается относительно предыдущего периода той же длины
+func (h *LeaderboardHandler) Get(c echo.Context) error {
+	q := model.LeaderboardQuery{
+		Metric:   model.LeaderboardMetric(c.QueryParam("metric")),
+		Language: strings.ToLower(strings.TrimSpace(c.QueryParam("language"))),
+	}
+
+	switch q.Metric {
+	case "":
+		q.Metric = model.MetricHonorGain
+	case model.MetricHonor, model.MetricRank, model.MetricLanguage,
+		model.MetricHonorGain, model.MetricCompletions:
+	default:
+		return badRequest(c, "metric must be honor, rank, language, honor_gain or completions")
+	}
+	if q.Metric == model.MetricLanguage && q.Language == "" {
+		return badRequest(c, "language is required for metric=language")
+	}
+
+	switch c.QueryParam("scope") {
+	case "", "tracked":
+	case "all":
+		q.AllUsers = true
+	default:
+		return badRequest(c, "scope must be tracked or all")
+	}
+
+	now := time.Now().UTC()
+	rawFrom, rawTo := c.QueryParam("from"), c.QueryParam("to")
+	if rawFrom != "" || rawTo != "" {
+		if rawFrom == "" {
+			return badRequest(c, "from is required with to")
+		}
+		from, err := parseTimeParam(rawFrom, false)
+		if err != nil {
+			return badRequest(c, fmt.Sprintf("invalid from: %v", err))
+		}
+		to := now
+		if rawTo != "" {
+			if to, err = parseTimeParam(rawTo, true); err != nil {
+				return badRequest(c, fmt.Sprintf("invalid to: %v", err))
+			}
+		}
+		if !from.Before(to) {
+			return badRequest(c, "from must be before to")
+		}
+		q.From, q.To = from, to
+		q.PreviousFrom = from.Add(-to.Sub(from))
+	} else {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		switch c.QueryParam("period") {
+		case "", "month":
+			q.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+			q.PreviousFrom = q.From.AddDate(0, -1, 0)
+		case "week":
+			q.From = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+			q.PreviousFrom = q.From.AddDate(0, 0, -7)
+		default:
+			return badRequest(c, "period must be week or month")
+		}
+		q.To = now
+	}
+
+	board, err := h.leaderboardService.Leaderboard(c.Request().Context(), q)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, board)
+}
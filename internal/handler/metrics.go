@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"SolverAPI/pkg/codewars"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CodewarsStats - источник метрик клиента Codewars
+type CodewarsStats interface {
+	Stats() codewars.Stats
+}
+
+type MetricsHandler struct {
+	codewars CodewarsStats
+}
+
+func NewMetricsHandler(cw CodewarsStats) *MetricsHandler {
+	return &MetricsHandler{codewars: cw}
+}
+
+func (h *MetricsHandler) Get(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"codewars": h.codewars.Stats(),
+	})
+}
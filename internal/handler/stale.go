@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// setStaleHeaders помечает ответ, отданный из БД вместо Codewars:
+// Warning 110 с датой последней синхронизации и Age в секундах
+func setStaleHeaders(c echo.Context, syncedAt time.Time) {
+	header := c.Response().Header()
+	if syncedAt.IsZero() {
+		header.Set("Warning", `110 - "Response is Stale"`)
+		return
+	}
+
+	header.Set("Warning", fmt.Sprintf(`110 - "Response is Stale" "%s"`, syncedAt.UTC().Format(http.TimeFormat)))
+	if age := time.Since(syncedAt); age > 0 {
+		header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	}
+}
+
+// setSyncedHeaders сообщает время последней синхронизации данных с Codewars:
+// Last-Modified и Age в секундах
+func setSyncedHeaders(c echo.Context, syncedAt time.Time) {
+	if syncedAt.IsZero() {
+		return
+	}
+
+	header := c.Response().Header()
+	header.Set("Last-Modified", syncedAt.UTC().Format(http.TimeFormat))
+	age := time.Since(syncedAt)
+	if age < 0 {
+		age = 0
+	}
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+}
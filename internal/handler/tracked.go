@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"SolverAPI/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TrackedUserHandler struct {
+	trackingService *service.TrackingService
+}
+
+func NewTrackedUserHandler(ts *service.TrackingService) *TrackedUserHandler {
+	return &TrackedUserHandler{trackingService: ts}
+}
+
+// List возвращает отслеживаемых пользователей с результатами последних синхронизаций
+func (h *TrackedUserHandler) List(c echo.Context) error {
+	users, err := h.trackingService.List(c.Request().Context())
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+// Track добавляет пользователя в реестр: 201, если добавлен, 200, если уже был
+func (h *TrackedUserHandler) Track(c echo.Context) error {
+	user, created, err := h.trackingService.Track(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, user)
+}
+
+// Untrack удаляет пользователя из реестра
+func (h *TrackedUserHandler) Untrack(c echo.Context) error {
+	if err := h.trackingService.Untrack(c.Request().Context(), c.Param("username")); err != nil {
+		return respondError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
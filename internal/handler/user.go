@@ -1,34 +1,233 @@
 package handler
 
 import (
+	"SolverAPI/internal/model"
 	"SolverAPI/internal/service"
-	"SolverAPI/pkg/codewars"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 type UserHandler struct {
-	CodewarsClient *codewars.Client
-	userService    *service.UserService
+	userService *service.UserService
 }
 
-func NewUserHandler(cwClient *codewars.Client, userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{
-		CodewarsClient: cwClient,
-		userService:    userService,
+		userService: userService,
 	}
 }
 
+// Размер страницы списка пользователей
+const (
+	defaultUsersLimit = 50
+	maxUsersLimit     = 200
+)
+
+// ListUsers возвращает сохраненных пользователей постранично.
+// Параметры: clan, min_rank (4kyu, 1dan, ...), language, synced_since (YYYY-MM-DD или RFC3339),
+// sort (honor|rank|username|updated_at), order (asc|desc), limit, cursor (из next_cursor)
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	query := model.UserQuery{
+		Clan:     c.QueryParam("clan"),
+		Language: strings.ToLower(strings.TrimSpace(c.QueryParam("language"))),
+		Sort:     model.UserSort(c.QueryParam("sort")),
+		Limit:    defaultUsersLimit,
+	}
+
+	switch query.Sort {
+	case "":
+		query.Sort = model.SortByHonor
+	case model.SortByHonor, model.SortByRank, model.SortByUsername, model.SortByUpdatedAt:
+	default:
+		return badRequest(c, "sort must be honor, rank, username or updated_at")
+	}
+
+	switch c.QueryParam("order") {
+	case "":
+		query.Desc = query.Sort.DefaultDesc()
+	case "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return badRequest(c, "order must be asc or desc")
+	}
+
+	if raw := c.QueryParam("min_rank"); raw != "" {
+		rank, err := parseRank(raw)
+		if err != nil {
+			return badRequest(c, err.Error())
+		}
+		query.MinRank = rank
+	}
+
+	if raw := c.QueryParam("synced_since"); raw != "" {
+		t, err := parseTimeParam(raw, false)
+		if err != nil {
+			return badRequest(c, fmt.Sprintf("invalid synced_since: %v", err))
+		}
+		query.SyncedSince = t
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxUsersLimit {
+			return badRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxUsersLimit))
+		}
+		query.Limit = limit
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		cursor, err := model.DecodeUserCursor(raw)
+		if err != nil {
+			return badRequest(c, err.Error())
+		}
+		if cursor.Sort != query.Sort || cursor.Desc != query.Desc {
+			return badRequest(c, "cursor was issued for a different sort")
+		}
+		if cursor.Filters != query.FilterHash() {
+			return badRequest(c, "cursor was issued for different filters")
+		}
+		query.After = cursor
+	}
+
+	users, next, err := h.userService.ListUsers(c.Request().Context(), query)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	resp := map[string]interface{}{
+		"users": users,
+	}
+	if next != nil {
+		resp["next_cursor"] = next.Encode()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetUser возвращает пользователя из БД, если он синхронизирован недавно,
+// иначе синхронизирует его с Codewars. ?refresh=true - синхронизировать всегда
 func (h *UserHandler) GetUser(c echo.Context) error {
 	username := c.Param("username")
 
-	user, err := h.userService.SyncUser(c.Request().Context(), username)
+	refresh := false
+	if raw := c.QueryParam("refresh"); raw != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(raw); err != nil {
+			return badRequest(c, "refresh must be true or false")
+		}
+	}
+
+	user, err := h.userService.GetUser(c.Request().Context(), username, refresh)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		return respondError(c, err)
+	}
+	setSyncedHeaders(c, user.SyncedAt)
+	if user.Stale {
+		setStaleHeaders(c, user.SyncedAt)
 	}
 
 	return c.JSON(http.StatusOK, user)
 }
+
+func (h *UserHandler) GetCompletedChallenges(c echo.Context) error {
+	username := c.Param("username")
+
+	challenges, err := h.userService.SyncCompletedChallenges(c.Request().Context(), username)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, challenges)
+}
+
+func (h *UserHandler) GetAuthoredChallenges(c echo.Context) error {
+	username := c.Param("username")
+
+	challenges, err := h.userService.SyncAuthoredChallenges(c.Request().Context(), username)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, challenges)
+}
+
+// Ограничения запроса истории
+const (
+	defaultHistoryRange = 90 * 24 * time.Hour
+	maxHistoryDays      = 2 * 366
+)
+
+// GetHistory возвращает ряд хонора и рангов пользователя.
+// Параметры: from, to (YYYY-MM-DD или RFC3339; по умолчанию последние 90 дней),
+// resolution (day|week, по умолчанию day)
+func (h *UserHandler) GetHistory(c echo.Context) error {
+	username := c.Param("username")
+
+	to := time.Now().UTC()
+	if raw := c.QueryParam("to"); raw != "" {
+		t, err := parseTimeParam(raw, true)
+		if err != nil {
+			return badRequest(c, fmt.Sprintf("invalid to: %v", err))
+		}
+		to = t
+	}
+
+	from := to.Add(-defaultHistoryRange)
+	if raw := c.QueryParam("from"); raw != "" {
+		t, err := parseTimeParam(raw, false)
+		if err != nil {
+			return badRequest(c, fmt.Sprintf("invalid from: %v", err))
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return badRequest(c, "from must be before to")
+	}
+	if to.Sub(from) > maxHistoryDays*24*time.Hour {
+		return badRequest(c, fmt.Sprintf("range must not exceed %d days", maxHistoryDays))
+	}
+
+	resolution := model.HistoryResolution(c.QueryParam("resolution"))
+	switch resolution {
+	case "":
+		resolution = model.ResolutionDay
+	case model.ResolutionDay, model.ResolutionWeek:
+	default:
+		return badRequest(c, "resolution must be day or week")
+	}
+
+	points, err := h.userService.UserHistory(c.Request().Context(), username, from, to, resolution)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"username":   username,
+		"from":       from,
+		"to":         to,
+		"resolution": resolution,
+		"points":     points,
+	})
+}
+
+// parseTimeParam разбирает дату YYYY-MM-DD или время в RFC3339.
+// Дата - это полночь UTC, а для конца интервала (endOfDay) - полночь следующего дня
+func parseTimeParam(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("use YYYY-MM-DD or RFC3339")
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// CompletedChallenge - задача, решенная пользователем на Codewars
+type CompletedChallenge struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Slug               string    `json:"slug"`
+	CompletedAt        time.Time `json:"completedAt"`
+	CompletedLanguages []string  `json:"completedLanguages"`
+}
+
+// AuthoredChallenge - задача, созданная пользователем на Codewars
+type AuthoredChallenge struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Rank        *int     `json:"rank"` // null для задач в бете
+	RankName    string   `json:"rankName"`
+	Tags        []string `json:"tags"`
+	Languages   []string `json:"languages"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "fmt"
+
+// Clan - известные нам пользователи клана Codewars и сводные показатели по ним
+type Clan struct {
+	Name            string `json:"name"`
+	MemberCount     int    `json:"member_count"`
+	TotalHonor      int    `json:"total_honor"`
+	AverageRank     int    `json:"average_rank"` // В формате Codewars: -8..-1 kyu, 1..8 dan; 0 - нет данных
+	AverageRankName string `json:"average_rank_name,omitempty"`
+	Users           []User `json:"users"`
+}
+
+// RankName возвращает название ранга Codewars: -6 - "6 kyu", 1 - "1 dan"
+func RankName(rank int) string {
+	switch {
+	case rank < 0:
+		return fmt.Sprintf("%d kyu", -rank)
+	case rank > 0:
+		return fmt.Sprintf("%d dan", rank)
+	default:
+		return ""
+	}
+}
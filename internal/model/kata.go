@@ -2,16 +2,71 @@ package model
 
 import "time"
 
+// KataRank - ранг задачи. ID равен null для задач в бете
+type KataRank struct {
+	ID    *int   `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// KataUser - автор или модератор задачи
+type KataUser struct {
+	Username string `json:"username"`
+	URL      string `json:"url"`
+}
+
 type CodewarsKata struct {
+	ID                 string     `json:"id"`
+	Name               string     `json:"name"`
+	Slug               string     `json:"slug"`
+	URL                string     `json:"url"`
+	Category           string     `json:"category"`
+	Description        string     `json:"description"`
+	Tags               []string   `json:"tags"`
+	Languages          []string   `json:"languages"`
+	Rank               KataRank   `json:"rank"`
+	CreatedBy          *KataUser  `json:"createdBy"`
+	ApprovedBy         *KataUser  `json:"approvedBy"`
+	PublishedAt        *time.Time `json:"publishedAt"`
+	ApprovedAt         *time.Time `json:"approvedAt"`
+	TotalAttempts      int        `json:"totalAttempts"`
+	TotalCompleted     int        `json:"totalCompleted"`
+	TotalStars         int        `json:"totalStars"`
+	VoteScore          int        `json:"voteScore"`
+	ContributorsWanted bool       `json:"contributorsWanted"`
+}
+
+type Kata struct {
+	CodewarsKata
+	AddedAt  time.Time `json:"added_at"`
+	SyncedAt time.Time `json:"-"`               // Время последнего получения задачи из Codewars
+	Stale    bool      `json:"stale,omitempty"` // Задача отдана из БД, потому что Codewars недоступен
+}
+
+// KataFilter - фильтры поиска задач на codewars.com/kata/search
+type KataFilter struct {
+	Query    string   `json:"query,omitempty"`
+	Language string   `json:"language,omitempty"`
+	Ranks    []int    `json:"ranks,omitempty"` // -8..-1 для kyu, 1..8 для dan
+	Tags     []string `json:"tags,omitempty"`
+	Status   string   `json:"status,omitempty"` // approved | beta
+	OrderBy  string   `json:"order_by,omitempty"`
+}
+
+// KataSummary - задача из результатов поиска
+type KataSummary struct {
 	ID        string   `json:"id"`
 	Name      string   `json:"name"`
-	Slug      string   `json:"slug"`
-	URL       string   `json:"url"`
-	Tags      []string `json:"tags"`
+	Rank      *int     `json:"rank"` // null для задач в бете
+	RankName  string   `json:"rank_name"`
 	Languages []string `json:"languages"`
 }
 
-type Kata struct {
-	CodewarsKata
-	AddedAt time.Time `json:"added_at"`
+// KataBufferEntry - ID задачи, найденный поиском по фильтру, в постоянном буфере
+type KataBufferEntry struct {
+	KataID       string     `json:"kata_id"`
+	Filter       KataFilter `json:"filter"`
+	FilterKey    string     `json:"filter_key"`
+	DiscoveredAt time.Time  `json:"discovered_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
 }
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// LeaderboardMetric - показатель, по которому строится рейтинг
+type LeaderboardMetric string
+
+const (
+	MetricHonor       LeaderboardMetric = "honor"       // Хонор на конец периода
+	MetricRank        LeaderboardMetric = "rank"        // Общий ранг и очки ранга на конец периода
+	MetricLanguage    LeaderboardMetric = "language"    // Ранг по языку на конец периода
+	MetricHonorGain   LeaderboardMetric = "honor_gain"  // Прирост хонора за период
+	MetricCompletions LeaderboardMetric = "completions" // Решенные за период задачи
+)
+
+// LeaderboardQuery - параметры рейтинга. Предыдущий период - [PreviousFrom, From)
+type LeaderboardQuery struct {
+	Metric       LeaderboardMetric
+	Language     string // Для MetricLanguage
+	From         time.Time
+	To           time.Time
+	PreviousFrom time.Time
+	AllUsers     bool // false - только отслеживаемые пользователи
+}
+
+// LeaderboardEntry - место пользователя в рейтинге.
+// Равные значения делят место, следующее место пропускается (1, 2, 2, 4)
+type LeaderboardEntry struct {
+	Position         int    `json:"position"`
+	Username         string `json:"username"`
+	Value            int    `json:"value"`
+	Score            int    `json:"score,omitempty"`     // Очки ранга для MetricRank и MetricLanguage
+	RankName         string `json:"rank_name,omitempty"` // Название ранга для MetricRank и MetricLanguage
+	PreviousPosition *int   `json:"previous_position"`   // null - пользователя не было в рейтинге
+	Change           *int   `json:"change"`              // Положительное - поднялся
+}
+
+type Leaderboard struct {
+	Metric       LeaderboardMetric  `json:"metric"`
+	Language     string             `json:"language,omitempty"`
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	PreviousFrom time.Time          `json:"previous_from"`
+	Entries      []LeaderboardEntry `json:"entries"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// UserSnapshot - состояние профиля пользователя на момент синхронизации
+type UserSnapshot struct {
+	Username       string          `json:"username"`
+	Honor          int             `json:"honor"`
+	OverallRank    Rank            `json:"overall_rank"`
+	LanguageRanks  map[string]Rank `json:"language_ranks"`
+	TotalCompleted int             `json:"total_completed"`
+	TakenAt        time.Time       `json:"taken_at"`
+}
+
+// HistoryResolution - размер периода в ряду истории
+type HistoryResolution string
+
+const (
+	ResolutionDay  HistoryResolution = "day"
+	ResolutionWeek HistoryResolution = "week"
+)
+
+// HistoryPoint - состояние профиля на конец периода, начинающегося в Start
+type HistoryPoint struct {
+	Start          time.Time       `json:"start"`
+	Honor          int             `json:"honor"`
+	OverallRank    Rank            `json:"overall_rank"`
+	LanguageRanks  map[string]Rank `json:"language_ranks"`
+	TotalCompleted int             `json:"total_completed"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Источники отслеживания пользователя
+const (
+	TrackSourceManual = "manual" // Добавлен через API
+	TrackSourceClan   = "clan"   // Добавлен автоматически как соклановец
+)
+
+// TrackedUser - пользователь, которого планировщик периодически синхронизирует
+type TrackedUser struct {
+	Username      string     `json:"username"`
+	Source        string     `json:"source"`
+	AddedAt       time.Time  `json:"added_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+}
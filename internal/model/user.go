@@ -2,13 +2,41 @@ package model
 
 import "time"
 
+// Rank описывает ранг пользователя (общий или по языку)
+type Rank struct {
+	Rank  int    `json:"rank"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Score int    `json:"score"`
+}
+
+type Ranks struct {
+	Overall   Rank            `json:"overall"`
+	Languages map[string]Rank `json:"languages"`
+}
+
+type CodeChallenges struct {
+	TotalAuthored  int `json:"totalAuthored"`
+	TotalCompleted int `json:"totalCompleted"`
+}
+
 type CodewarsUser struct {
-	Username  string    `json:"username"`
-	Honor     int       `json:"honor"`
-	CreatedAt time.Time // Для хранения в БД
+	ID                  string         `json:"id"`
+	Username            string         `json:"username"`
+	Name                string         `json:"name"`
+	Honor               int            `json:"honor"`
+	Clan                string         `json:"clan"`
+	LeaderboardPosition *int           `json:"leaderboardPosition"` // null для пользователей вне рейтинга
+	Skills              []string       `json:"skills"`
+	Ranks               Ranks          `json:"ranks"`
+	CodeChallenges      CodeChallenges `json:"codeChallenges"`
+	CreatedAt           time.Time      // Для хранения в БД
+	FetchedAt           time.Time      `json:"-"` // Когда ответ получен от Codewars (для ответа из кэша - время запроса)
 }
 
 type User struct {
 	CodewarsUser
 	CreatedAt time.Time
+	SyncedAt  time.Time `json:"synced_at"`       // Время последней успешной синхронизации с Codewars
+	Stale     bool      `json:"stale,omitempty"` // Данные отданы из БД, потому что Codewars недоступен
 }
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UserSort - поле сортировки списка пользователей
+type UserSort string
+
+const (
+	SortByHonor     UserSort = "honor"      // По умолчанию по убыванию
+	SortByRank      UserSort = "rank"       // Общий ранг, затем очки ранга; по убыванию, без ранга - в конце
+	SortByUsername  UserSort = "username"   // По возрастанию
+	SortByUpdatedAt UserSort = "updated_at" // Время синхронизации; по убыванию
+)
+
+// DefaultDesc сообщает направление сортировки по умолчанию
+func (s UserSort) DefaultDesc() bool {
+	return s != SortByUsername
+}
+
+// UserQuery - фильтры, сортировка и страница списка пользователей
+type UserQuery struct {
+	Clan        string
+	MinRank     int // Минимальный общий ранг в формате Codewars (-8..-1, 1..8), 0 - без фильтра
+	Language    string
+	SyncedSince time.Time // Нулевое значение - без фильтра
+
+	Sort  UserSort
+	Desc  bool
+	After *UserCursor // Продолжить после этой записи, nil - первая страница
+	Limit int
+}
+
+// FilterHash - короткий отпечаток фильтров запроса. Курсор хранит его,
+// чтобы следующую страницу нельзя было запросить с другими фильтрами
+func (q UserQuery) FilterHash() string {
+	var since string
+	if !q.SyncedSince.IsZero() {
+		since = q.SyncedSince.UTC().Format(time.RFC3339Nano)
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%q|%d|%q|%s", q.Clan, q.MinRank, q.Language, since))
+	return hex.EncodeToString(sum[:8])
+}
+
+// UserCursor - позиция в списке пользователей: значения сортировки последней записи
+type UserCursor struct {
+	Sort      UserSort  `json:"o"`
+	Desc      bool      `json:"d,omitempty"`
+	Filters   string    `json:"f"` // UserQuery.FilterHash запроса, выдавшего курсор
+	Honor     int       `json:"h,omitempty"`
+	Rank      int       `json:"r,omitempty"`
+	RankScore int       `json:"s,omitempty"`
+	UpdatedAt time.Time `json:"u,omitempty"`
+	Username  string    `json:"n"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorAfter возвращает курсор, указывающий на пользователя в списке с сортировкой query
+func CursorAfter(query UserQuery, user User) *UserCursor {
+	return &UserCursor{
+		Sort:      query.Sort,
+		Desc:      query.Desc,
+		Filters:   query.FilterHash(),
+		Honor:     user.Honor,
+		Rank:      user.Ranks.Overall.Rank,
+		RankScore: user.Ranks.Overall.Score,
+		UpdatedAt: user.SyncedAt,
+		Username:  user.Username,
+	}
+}
+
+// Encode возвращает курсор в виде непрозрачной строки для API
+func (c *UserCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor разбирает строку, полученную из Encode
+func DecodeUserCursor(raw string) (*UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c UserCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Username == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	updated := time.Date(2024, 3, 1, 10, 30, 0, 123000000, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor UserCursor
+	}{
+		{"honor desc", UserCursor{Sort: SortByHonor, Desc: true, Filters: "abc", Honor: 1500, Username: "some_user"}},
+		{"rank with score", UserCursor{Sort: SortByRank, Desc: true, Rank: -4, RankScore: 1234, Username: "some_user"}},
+		{"unranked user", UserCursor{Sort: SortByRank, Rank: 0, Username: "newbie"}},
+		{"username asc", UserCursor{Sort: SortByUsername, Username: "ä-user_1"}},
+		{"updated at", UserCursor{Sort: SortByUpdatedAt, Desc: true, UpdatedAt: updated, Username: "some_user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeUserCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeUserCursor: %v", err)
+			}
+			if !got.UpdatedAt.Equal(tt.cursor.UpdatedAt) {
+				t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, tt.cursor.UpdatedAt)
+			}
+			got.UpdatedAt = tt.cursor.UpdatedAt
+			if *got != tt.cursor {
+				t.Errorf("cursor = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeUserCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"n":"ab"}`))},
+		{"not json", encode("honor:a")},
+		{"wrong field type", encode(`{"o":"honor","h":"high","n":"a"}`)},
+		{"empty username", encode(`{"o":"honor","h":10}`)},
+		{"empty object", encode(`{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeUserCursor(tt.raw); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestFilterHash(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := UserQuery{Clan: "clan", MinRank: -4, Language: "go", SyncedSince: since, Sort: SortByHonor, Desc: true, Limit: 50}
+
+	tests := []struct {
+		name   string
+		modify func(q *UserQuery)
+		same   bool
+	}{
+		{"clan", func(q *UserQuery) { q.Clan = "other" }, false},
+		{"clan removed", func(q *UserQuery) { q.Clan = "" }, false},
+		{"min rank", func(q *UserQuery) { q.MinRank = -3 }, false},
+		{"language", func(q *UserQuery) { q.Language = "python" }, false},
+		{"synced since", func(q *UserQuery) { q.SyncedSince = since.Add(time.Second) }, false},
+		{"synced since removed", func(q *UserQuery) { q.SyncedSince = time.Time{} }, false},
+		{"fields are not concatenated", func(q *UserQuery) { q.Clan, q.Language = "clan|go", "" }, false},
+		{"same instant in another zone", func(q *UserQuery) { q.SyncedSince = since.In(time.FixedZone("MSK", 3*60*60)) }, true},
+		{"sort and order", func(q *UserQuery) { q.Sort, q.Desc = SortByUsername, false }, true},
+		{"limit", func(q *UserQuery) { q.Limit = 10 }, true},
+		{"cursor", func(q *UserQuery) { q.After = &UserCursor{Username: "a"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := base
+			tt.modify(&q)
+			if same := q.FilterHash() == base.FilterHash(); same != tt.same {
+				t.Errorf("same hash = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestCursorAfterBindsQuery(t *testing.T) {
+	query := UserQuery{Clan: "clan", Language: "go", Sort: SortByRank, Desc: true}
+	user := User{
+		CodewarsUser: CodewarsUser{Username: "some_user", Honor: 900},
+		SyncedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	user.Ranks.Overall = Rank{Rank: -3, Score: 2000}
+
+	c, err := DecodeUserCursor(CursorAfter(query, user).Encode())
+	if err != nil {
+		t.Fatalf("DecodeUserCursor: %v", err)
+	}
+	if c.Sort != query.Sort || c.Desc != query.Desc || c.Filters != query.FilterHash() {
+		t.Errorf("cursor %+v is not bound to the query", c)
+	}
+	if c.Rank != -3 || c.RankScore != 2000 || c.Honor != 900 || c.Username != "some_user" {
+		t.Errorf("cursor values = %+v", c)
+	}
+
+	query.Language = "python"
+	if c.Filters == query.FilterHash() {
+		t.Error("cursor matches a query with other filters")
+	}
+}
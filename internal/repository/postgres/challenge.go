@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+type CompletedChallengeRepo struct {
+	db *sql.DB
+}
+
+func NewCompletedChallengeRepository(db *sql.DB) repository.CompletedChallengeRepository {
+	return &CompletedChallengeRepo{db: db}
+}
+
+func (r *CompletedChallengeRepo) SaveCompletedChallenges(ctx context.Context, username string, challenges []model.CompletedChallenge) error {
+	if len(challenges) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO completed_challenges (username, id, name, slug, completed_at, completed_languages)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (username, id) DO UPDATE SET
+            name = EXCLUDED.name,
+            slug = EXCLUDED.slug,
+            completed_at = EXCLUDED.completed_at,
+            completed_languages = EXCLUDED.completed_languages
+    `
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, ch := range challenges {
+		languagesJSON, _ := json.Marshal(ch.CompletedLanguages)
+		if _, err := stmt.ExecContext(ctx,
+			username,
+			ch.ID,
+			ch.Name,
+			ch.Slug,
+			ch.CompletedAt,
+			languagesJSON,
+		); err != nil {
+			return fmt.Errorf("failed to save challenge %s: %w", ch.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *CompletedChallengeRepo) GetCompletedChallenges(ctx context.Context, username string) ([]model.CompletedChallenge, error) {
+	query := `
+        SELECT id, name, slug, completed_at, completed_languages
+        FROM completed_challenges
+        WHERE username = $1
+        ORDER BY completed_at DESC
+    `
+	rows, err := r.db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	challenges := make([]model.CompletedChallenge, 0)
+	for rows.Next() {
+		var ch model.CompletedChallenge
+		var languagesJSON []byte
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Slug, &ch.CompletedAt, &languagesJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan challenge: %w", err)
+		}
+		json.Unmarshal(languagesJSON, &ch.CompletedLanguages)
+		challenges = append(challenges, ch)
+	}
+
+	return challenges, rows.Err()
+}
+
+type AuthoredChallengeRepo struct {
+	db *sql.DB
+}
+
+func NewAuthoredChallengeRepository(db *sql.DB) repository.AuthoredChallengeRepository {
+	return &AuthoredChallengeRepo{db: db}
+}
+
+// SaveAuthoredChallenges заменяет список задач автора актуальным:
+// задачи, которых больше нет в ответе Codewars, удаляются
+func (r *AuthoredChallengeRepo) SaveAuthoredChallenges(ctx context.Context, username string, challenges []model.AuthoredChallenge) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM authored_challenges WHERE username = $1`, username); err != nil {
+		return fmt.Errorf("failed to clear authored challenges: %w", err)
+	}
+
+	query := `
+        INSERT INTO authored_challenges (username, id, name, description, rank, rank_name, tags, languages, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+    `
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, ch := range challenges {
+		tagsJSON, _ := json.Marshal(ch.Tags)
+		languagesJSON, _ := json.Marshal(ch.Languages)
+		if _, err := stmt.ExecContext(ctx,
+			username,
+			ch.ID,
+			ch.Name,
+			ch.Description,
+			ch.Rank,
+			ch.RankName,
+			tagsJSON,
+			languagesJSON,
+		); err != nil {
+			return fmt.Errorf("failed to save authored challenge %s: %w", ch.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthoredChallengeRepo) GetAuthoredChallenges(ctx context.Context, username string) ([]model.AuthoredChallenge, error) {
+	query := `
+        SELECT id, name, description, rank, rank_name, tags, languages
+        FROM authored_challenges
+        WHERE username = $1
+        ORDER BY name
+    `
+	rows, err := r.db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	challenges := make([]model.AuthoredChallenge, 0)
+	for rows.Next() {
+		var ch model.AuthoredChallenge
+		var rank sql.NullInt64
+		var tagsJSON, languagesJSON []byte
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Description, &rank, &ch.RankName, &tagsJSON, &languagesJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan authored challenge: %w", err)
+		}
+		if rank.Valid {
+			v := int(rank.Int64)
+			ch.Rank = &v
+		}
+		json.Unmarshal(tagsJSON, &ch.Tags)
+		json.Unmarshal(languagesJSON, &ch.Languages)
+		challenges = append(challenges, ch)
+	}
+
+	return challenges, rows.Err()
+}
@@ -7,6 +7,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type KataRepo struct {
@@ -17,19 +21,40 @@ func NewKataRepository(db *sql.DB) repository.KataRepository {
 	return &KataRepo{db: db}
 }
 
+// SaveKata сохраняет задачу. added_at и synced_at пишутся в UTC, как и у users
 func (r *KataRepo) SaveKata(ctx context.Context, kata *model.Kata) error {
 	tagsJSON, _ := json.Marshal(kata.Tags)
 	languagesJSON, _ := json.Marshal(kata.Languages)
 
 	query := `
-        INSERT INTO katas (id, name, slug, url, tags, languages, added_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        INSERT INTO katas (
+            id, name, slug, url, tags, languages, added_at,
+            category, description, rank_id, rank_name, rank_color,
+            created_by, approved_by, published_at, approved_at,
+            total_attempts, total_completed, total_stars, vote_score, contributors_wanted, synced_at
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
         ON CONFLICT (id) DO UPDATE SET
             name = EXCLUDED.name,
             slug = EXCLUDED.slug,
             url = EXCLUDED.url,
             tags = EXCLUDED.tags,
-            languages = EXCLUDED.languages
+            languages = EXCLUDED.languages,
+            category = EXCLUDED.category,
+            description = EXCLUDED.description,
+            rank_id = EXCLUDED.rank_id,
+            rank_name = EXCLUDED.rank_name,
+            rank_color = EXCLUDED.rank_color,
+            created_by = EXCLUDED.created_by,
+            approved_by = EXCLUDED.approved_by,
+            published_at = EXCLUDED.published_at,
+            approved_at = EXCLUDED.approved_at,
+            total_attempts = EXCLUDED.total_attempts,
+            total_completed = EXCLUDED.total_completed,
+            total_stars = EXCLUDED.total_stars,
+            vote_score = EXCLUDED.vote_score,
+            contributors_wanted = EXCLUDED.contributors_wanted,
+            synced_at = EXCLUDED.synced_at
     `
 	_, err := r.db.ExecContext(ctx, query,
 		kata.ID,
@@ -38,22 +63,46 @@ func (r *KataRepo) SaveKata(ctx context.Context, kata *model.Kata) error {
 		kata.URL,
 		tagsJSON,
 		languagesJSON,
-		kata.AddedAt,
+		kata.AddedAt.UTC(),
+		kata.Category,
+		kata.Description,
+		kata.Rank.ID,
+		kata.Rank.Name,
+		kata.Rank.Color,
+		kataUserJSON(kata.CreatedBy),
+		kataUserJSON(kata.ApprovedBy),
+		kata.PublishedAt,
+		kata.ApprovedAt,
+		kata.TotalAttempts,
+		kata.TotalCompleted,
+		kata.TotalStars,
+		kata.VoteScore,
+		kata.ContributorsWanted,
+		kata.SyncedAt.UTC(),
 	)
 	return err
 }
 
-func (r *KataRepo) GetRandomKata(ctx context.Context) (*model.Kata, error) {
+func (r *KataRepo) GetRandomKata(ctx context.Context, filter model.KataFilter) (*model.Kata, error) {
+	where, args := kataFilterWhere(filter)
 	query := `
-        SELECT id, name, slug, url, tags, languages, added_at 
+        SELECT id, name, slug, url, tags, languages, added_at,
+               category, description, rank_id, rank_name, rank_color,
+               created_by, approved_by, published_at, approved_at,
+               total_attempts, total_completed, total_stars, vote_score, contributors_wanted,
+               synced_at
         FROM katas
+        ` + where + `
         ORDER BY RANDOM()
         LIMIT 1
     `
-	row := r.db.QueryRowContext(ctx, query)
+	row := r.db.QueryRowContext(ctx, query, args...)
 
 	var kata model.Kata
 	var tagsJSON, languagesJSON []byte
+	var rankID sql.NullInt64
+	var createdBy, approvedBy []byte
+	var publishedAt, approvedAt sql.NullTime
 
 	err := row.Scan(
 		&kata.ID,
@@ -63,13 +112,99 @@ func (r *KataRepo) GetRandomKata(ctx context.Context) (*model.Kata, error) {
 		&tagsJSON,
 		&languagesJSON,
 		&kata.AddedAt,
+		&kata.Category,
+		&kata.Description,
+		&rankID,
+		&kata.Rank.Name,
+		&kata.Rank.Color,
+		&createdBy,
+		&approvedBy,
+		&publishedAt,
+		&approvedAt,
+		&kata.TotalAttempts,
+		&kata.TotalCompleted,
+		&kata.TotalStars,
+		&kata.VoteScore,
+		&kata.ContributorsWanted,
+		&kata.SyncedAt,
 	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrKataNotFound
+		}
 		return nil, fmt.Errorf("failed to scan kata: %w", err)
 	}
 
 	json.Unmarshal(tagsJSON, &kata.Tags)
 	json.Unmarshal(languagesJSON, &kata.Languages)
 
+	if rankID.Valid {
+		id := int(rankID.Int64)
+		kata.Rank.ID = &id
+	}
+	if createdBy != nil {
+		json.Unmarshal(createdBy, &kata.CreatedBy)
+	}
+	if approvedBy != nil {
+		json.Unmarshal(approvedBy, &kata.ApprovedBy)
+	}
+	kata.PublishedAt = nullTimePtr(publishedAt)
+	kata.ApprovedAt = nullTimePtr(approvedAt)
+
 	return &kata, nil
 }
+
+// kataFilterWhere строит условие WHERE по фильтру задач.
+// Сортировка фильтра не учитывается: задача выбирается случайно
+func kataFilterWhere(filter model.KataFilter) (string, []any) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Query != "" {
+		conds = append(conds, "name ILIKE '%' || "+arg(filter.Query)+" || '%'")
+	}
+	if filter.Language != "" {
+		conds = append(conds, "languages ? "+arg(filter.Language))
+	}
+	if len(filter.Ranks) > 0 {
+		ranks := make([]int64, len(filter.Ranks))
+		for i, rank := range filter.Ranks {
+			ranks[i] = int64(rank)
+		}
+		conds = append(conds, "rank_id = ANY("+arg(pq.Array(ranks))+")")
+	}
+	if len(filter.Tags) > 0 {
+		conds = append(conds, "tags ?& "+arg(pq.Array(filter.Tags)))
+	}
+	switch filter.Status {
+	case "approved":
+		conds = append(conds, "rank_id IS NOT NULL")
+	case "beta":
+		conds = append(conds, "rank_id IS NULL")
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// kataUserJSON сериализует автора задачи для JSONB-колонки, nil сохраняется как NULL
+func kataUserJSON(u *model.KataUser) sql.NullString {
+	if u == nil {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(u)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
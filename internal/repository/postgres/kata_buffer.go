@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type KataBufferRepo struct {
+	db *sql.DB
+}
+
+func NewKataBufferRepository(db *sql.DB) repository.KataBufferRepository {
+	return &KataBufferRepo{db: db}
+}
+
+func (r *KataBufferRepo) LoadKataBuffer(ctx context.Context) ([]model.KataBufferEntry, error) {
+	query := `
+        SELECT filter_key, kata_id, filter, discovered_at, last_seen_at
+        FROM kata_buffer
+        ORDER BY filter_key, discovered_at
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]model.KataBufferEntry, 0)
+	for rows.Next() {
+		var entry model.KataBufferEntry
+		var filterJSON []byte
+		if err := rows.Scan(&entry.FilterKey, &entry.KataID, &filterJSON, &entry.DiscoveredAt, &entry.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan buffer entry: %w", err)
+		}
+		json.Unmarshal(filterJSON, &entry.Filter)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *KataBufferRepo) MergeKataBuffer(ctx context.Context, filter model.KataFilter, filterKey string, ids []string, seenAt, staleBefore time.Time) error {
+	filterJSON, _ := json.Marshal(filter)
+	idsJSON, _ := json.Marshal(ids)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO kata_buffer (filter_key, kata_id, filter, discovered_at, last_seen_at)
+        SELECT $1, id, $2, $3, $3
+        FROM jsonb_array_elements_text($4::jsonb) AS id
+        ON CONFLICT (filter_key, kata_id) DO UPDATE SET
+            last_seen_at = EXCLUDED.last_seen_at
+    `
+	if _, err := tx.ExecContext(ctx, query, filterKey, filterJSON, seenAt, idsJSON); err != nil {
+		return fmt.Errorf("failed to merge kata buffer: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM kata_buffer WHERE filter_key = $1 AND last_seen_at < $2`,
+		filterKey, staleBefore,
+	); err != nil {
+		return fmt.Errorf("failed to prune kata buffer: %w", err)
+	}
+
+	return tx.Commit()
+}
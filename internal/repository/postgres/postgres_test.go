@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+)
+
+// testDB подключается к тестовой БД из TEST_DB_DSN, применяет миграции
+// и очищает таблицы. Без TEST_DB_DSN тест пропускается
+func testDB(t *testing.T, tables ...string) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations", dsn)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	m.Close()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, table := range tables {
+		if _, err := db.Exec("TRUNCATE " + table); err != nil {
+			t.Fatalf("failed to truncate %s: %v", table, err)
+		}
+	}
+	return db
+}
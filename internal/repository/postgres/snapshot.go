@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type UserSnapshotRepo struct {
+	db *sql.DB
+}
+
+func NewUserSnapshotRepository(db *sql.DB) repository.UserSnapshotRepository {
+	return &UserSnapshotRepo{db: db}
+}
+
+func (r *UserSnapshotRepo) AppendUserSnapshot(ctx context.Context, snapshot model.UserSnapshot) (bool, error) {
+	languageRanksJSON, _ := json.Marshal(snapshot.LanguageRanks)
+	if snapshot.LanguageRanks == nil {
+		languageRanksJSON = []byte("{}")
+	}
+
+	// Снимок пропускается, если последний снимок пользователя совпадает с ним.
+	// Параметры приводятся к типам колонок один раз в v: иначе $1 и $2
+	// получают разные выведенные типы в списке INSERT и в условии
+	query := `
+        WITH v AS (
+            SELECT $1::varchar AS username,
+                   $2::integer AS honor,
+                   $3::integer AS overall_rank,
+                   $4::varchar AS overall_rank_name,
+                   $5::varchar AS overall_rank_color,
+                   $6::integer AS overall_rank_score,
+                   $7::jsonb AS language_ranks,
+                   $8::integer AS total_completed,
+                   $9::timestamp AS taken_at
+        )
+        INSERT INTO user_snapshots (
+            username, honor, overall_rank, overall_rank_name, overall_rank_color,
+            overall_rank_score, language_ranks, total_completed, taken_at
+        )
+        SELECT v.username, v.honor, v.overall_rank, v.overall_rank_name, v.overall_rank_color,
+               v.overall_rank_score, v.language_ranks, v.total_completed, v.taken_at
+        FROM v
+        WHERE NOT EXISTS (
+            SELECT 1
+            FROM (
+                SELECT honor, overall_rank, overall_rank_score, language_ranks, total_completed
+                FROM user_snapshots
+                WHERE username = v.username
+                ORDER BY taken_at DESC
+                LIMIT 1
+            ) last
+            WHERE last.honor = v.honor
+              AND last.overall_rank = v.overall_rank
+              AND last.overall_rank_score = v.overall_rank_score
+              AND last.language_ranks = v.language_ranks
+              AND last.total_completed = v.total_completed
+        )
+    `
+	res, err := r.db.ExecContext(ctx, query,
+		snapshot.Username,
+		snapshot.Honor,
+		snapshot.OverallRank.Rank,
+		snapshot.OverallRank.Name,
+		snapshot.OverallRank.Color,
+		snapshot.OverallRank.Score,
+		languageRanksJSON,
+		snapshot.TotalCompleted,
+		snapshot.TakenAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to append user snapshot: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *UserSnapshotRepo) GetUserSnapshots(ctx context.Context, username string, from, to time.Time) ([]model.UserSnapshot, error) {
+	query := `
+        SELECT username, honor, overall_rank, overall_rank_name, overall_rank_color,
+               overall_rank_score, language_ranks, total_completed, taken_at
+        FROM user_snapshots
+        WHERE username = $1
+          AND taken_at <= $3
+          AND taken_at >= COALESCE(
+              (SELECT MAX(taken_at) FROM user_snapshots WHERE username = $1 AND taken_at < $2),
+              $2
+          )
+        ORDER BY taken_at
+    `
+	rows, err := r.db.QueryContext(ctx, query, username, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+func (r *UserSnapshotRepo) GetUserSnapshotsAt(ctx context.Context, usernames []string, at time.Time) ([]model.UserSnapshot, error) {
+	query := `
+        SELECT DISTINCT ON (username)
+               username, honor, overall_rank, overall_rank_name, overall_rank_color,
+               overall_rank_score, language_ranks, total_completed, taken_at
+        FROM user_snapshots
+        WHERE $1::text[] IS NULL OR username = ANY($1)
+        ORDER BY username,
+                 taken_at <= $2 DESC,
+                 CASE WHEN taken_at <= $2 THEN taken_at END DESC,
+                 taken_at
+    `
+	var names any
+	if usernames != nil {
+		names = pq.Array(usernames)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, names, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+func scanSnapshots(rows *sql.Rows) ([]model.UserSnapshot, error) {
+	var snapshots []model.UserSnapshot
+	for rows.Next() {
+		var s model.UserSnapshot
+		var languageRanksJSON []byte
+		if err := rows.Scan(
+			&s.Username,
+			&s.Honor,
+			&s.OverallRank.Rank,
+			&s.OverallRank.Name,
+			&s.OverallRank.Color,
+			&s.OverallRank.Score,
+			&languageRanksJSON,
+			&s.TotalCompleted,
+			&s.TakenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user snapshot: %w", err)
+		}
+		json.Unmarshal(languageRanksJSON, &s.LanguageRanks)
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAppendUserSnapshot(t *testing.T) {
+	repo := NewUserSnapshotRepository(testDB(t, "user_snapshots"))
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	snapshot := func(honor int, at time.Duration) model.UserSnapshot {
+		return model.UserSnapshot{
+			Username:       "some_user",
+			Honor:          honor,
+			OverallRank:    model.Rank{Rank: -4, Name: "4 kyu", Color: "blue", Score: 1500},
+			LanguageRanks:  map[string]model.Rank{"go": {Rank: -4, Name: "4 kyu", Color: "blue", Score: 1500}},
+			TotalCompleted: 10,
+			TakenAt:        start.Add(at),
+		}
+	}
+
+	steps := []struct {
+		name     string
+		snapshot model.UserSnapshot
+		want     bool
+	}{
+		{"first snapshot", snapshot(100, 0), true},
+		{"unchanged values are skipped", snapshot(100, time.Hour), false},
+		{"changed honor is appended", snapshot(120, 2*time.Hour), true},
+		{"return to earlier values is appended", snapshot(100, 3*time.Hour), true},
+	}
+
+	for _, step := range steps {
+		appended, err := repo.AppendUserSnapshot(ctx, step.snapshot)
+		if err != nil {
+			t.Fatalf("%s: AppendUserSnapshot: %v", step.name, err)
+		}
+		if appended != step.want {
+			t.Errorf("%s: appended = %v, want %v", step.name, appended, step.want)
+		}
+	}
+
+	got, err := repo.GetUserSnapshots(ctx, "some_user", start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetUserSnapshots: %v", err)
+	}
+	var honors []int
+	for _, s := range got {
+		honors = append(honors, s.Honor)
+	}
+	if len(honors) != 3 || honors[0] != 100 || honors[1] != 120 || honors[2] != 100 {
+		t.Errorf("honors = %v, want [100 120 100]", honors)
+	}
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type TrackedUserRepo struct {
+	db *sql.DB
+}
+
+func NewTrackedUserRepository(db *sql.DB) repository.TrackedUserRepository {
+	return &TrackedUserRepo{db: db}
+}
+
+// TrackUser добавляет пользователя или возвращает в реестр отказавшегося
+// от отслеживания: явное добавление отменяет отказ, результаты прошлых
+// синхронизаций сбрасываются
+func (r *TrackedUserRepo) TrackUser(ctx context.Context, username, source string) (bool, error) {
+	query := `
+        INSERT INTO tracked_users (username, source, added_at)
+        VALUES ($1, $2, NOW() AT TIME ZONE 'UTC')
+        ON CONFLICT (username) DO UPDATE SET
+            source = EXCLUDED.source,
+            added_at = EXCLUDED.added_at,
+            untracked_at = NULL,
+            last_attempt_at = NULL,
+            last_success_at = NULL,
+            last_error = '',
+            last_error_at = NULL
+        WHERE tracked_users.untracked_at IS NOT NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, username, source)
+	if err != nil {
+		return false, fmt.Errorf("failed to track user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// TrackClan не трогает существующие строки, в том числе отметки об отказе:
+// пользователь, убранный из реестра, не возвращается автоотслеживанием
+func (r *TrackedUserRepo) TrackClan(ctx context.Context, clan, except string) (int, error) {
+	query := `
+        INSERT INTO tracked_users (username, source, added_at)
+        SELECT username, $3, NOW() AT TIME ZONE 'UTC'
+        FROM users
+        WHERE clan = $1 AND username <> $2
+        ON CONFLICT (username) DO NOTHING
+    `
+	res, err := r.db.ExecContext(ctx, query, clan, except, model.TrackSourceClan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to track clan: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// UntrackUser отмечает отказ от отслеживания вместо удаления строки
+func (r *TrackedUserRepo) UntrackUser(ctx context.Context, username string) error {
+	query := `
+        UPDATE tracked_users
+        SET untracked_at = NOW() AT TIME ZONE 'UTC'
+        WHERE username = $1 AND untracked_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, username)
+	if err != nil {
+		return fmt.Errorf("failed to untrack user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotTracked
+	}
+	return nil
+}
+
+// trackedColumns - колонки реестра в порядке, который ожидает scanTrackedUser
+const trackedColumns = `
+        username, source, added_at, last_attempt_at, last_success_at, last_error, last_error_at`
+
+func scanTrackedUser(row rowScanner) (*model.TrackedUser, error) {
+	var user model.TrackedUser
+	var lastAttempt, lastSuccess, lastErrorAt sql.NullTime
+
+	err := row.Scan(
+		&user.Username,
+		&user.Source,
+		&user.AddedAt,
+		&lastAttempt,
+		&lastSuccess,
+		&user.LastError,
+		&lastErrorAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.LastAttemptAt = nullTimePtr(lastAttempt)
+	user.LastSuccessAt = nullTimePtr(lastSuccess)
+	user.LastErrorAt = nullTimePtr(lastErrorAt)
+
+	return &user, nil
+}
+
+func (r *TrackedUserRepo) GetTrackedUser(ctx context.Context, username string) (*model.TrackedUser, error) {
+	query := `SELECT` + trackedColumns + `
+        FROM tracked_users
+        WHERE username = $1 AND untracked_at IS NULL
+    `
+	user, err := scanTrackedUser(r.db.QueryRowContext(ctx, query, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrNotTracked
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *TrackedUserRepo) ListTrackedUsers(ctx context.Context) ([]model.TrackedUser, error) {
+	query := `SELECT` + trackedColumns + `
+        FROM tracked_users
+        WHERE untracked_at IS NULL
+        ORDER BY username
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tracked users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.TrackedUser{}
+	for rows.Next() {
+		user, err := scanTrackedUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tracked user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *TrackedUserRepo) RecordSyncResult(ctx context.Context, username string, at time.Time, syncErr error) error {
+	var query string
+	args := []any{username, at}
+	if syncErr == nil {
+		query = `
+            UPDATE tracked_users
+            SET last_attempt_at = $2, last_success_at = $2
+            WHERE username = $1
+        `
+	} else {
+		query = `
+            UPDATE tracked_users
+            SET last_attempt_at = $2, last_error = $3, last_error_at = $2
+            WHERE username = $1
+        `
+		args = append(args, syncErr.Error())
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record sync result: %w", err)
+	}
+	return nil
+}
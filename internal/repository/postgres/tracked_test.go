@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUntrackedUserIsNotReaddedByClan(t *testing.T) {
+	db := testDB(t, "tracked_users", "users")
+	users := NewUserRepository(db)
+	tracked := NewTrackedUserRepository(db)
+	ctx := context.Background()
+
+	for _, name := range []string{"some_user", "teammate"} {
+		user := &model.User{
+			CodewarsUser: model.CodewarsUser{Username: name, Clan: "clan"},
+			CreatedAt:    time.Now().UTC(),
+			SyncedAt:     time.Now().UTC(),
+		}
+		if err := users.CreateOrUpdateUser(ctx, user); err != nil {
+			t.Fatalf("CreateOrUpdateUser(%s): %v", name, err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		do     func() (int, error)
+		want   int
+		active bool
+	}{
+		{"clan adds teammate", func() (int, error) { return tracked.TrackClan(ctx, "clan", "some_user") }, 1, true},
+		{"untrack", func() (int, error) { return 0, tracked.UntrackUser(ctx, "teammate") }, 0, false},
+		{"clan skips untracked teammate", func() (int, error) { return tracked.TrackClan(ctx, "clan", "some_user") }, 0, false},
+		{"explicit track restores teammate", func() (int, error) {
+			created, err := tracked.TrackUser(ctx, "teammate", model.TrackSourceManual)
+			if created {
+				return 1, err
+			}
+			return 0, err
+		}, 1, true},
+	}
+
+	for _, step := range steps {
+		n, err := step.do()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if n != step.want {
+			t.Errorf("%s: affected = %d, want %d", step.name, n, step.want)
+		}
+
+		_, err = tracked.GetTrackedUser(ctx, "teammate")
+		if active := err == nil; active != step.active {
+			t.Errorf("%s: tracked = %v (err %v), want %v", step.name, active, err, step.active)
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotTracked) {
+			t.Fatalf("%s: GetTrackedUser: %v", step.name, err)
+		}
+	}
+
+	if err := tracked.UntrackUser(ctx, "nobody"); !errors.Is(err, repository.ErrNotTracked) {
+		t.Errorf("UntrackUser(nobody) = %v, want ErrNotTracked", err)
+	}
+}
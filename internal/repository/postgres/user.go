@@ -5,6 +5,9 @@ import (
 	"SolverAPI/internal/repository"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 type UserRepo struct {
@@ -15,23 +18,111 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 	return &UserRepo{db: db}
 }
 
+// CreateOrUpdateUser сохраняет профиль. Время пишется приложением в UTC,
+// как и остальные метки, с которыми сравнивается время из Go;
+// created_at при обновлении не меняется
 func (r *UserRepo) CreateOrUpdateUser(ctx context.Context, user *model.User) error {
+	skillsJSON, _ := json.Marshal(user.Skills)
+	languageRanksJSON, _ := json.Marshal(user.Ranks.Languages)
+
 	query := `
-        INSERT INTO users (username, honor, created_at, updated_at)
-        VALUES ($1, $2, NOW(), NOW())
-        ON CONFLICT (username) DO UPDATE
-        SET honor = $2, updated_at = NOW()
+        INSERT INTO users (
+            username, honor, codewars_id, name, clan, leaderboard_position, skills,
+            overall_rank, overall_rank_name, overall_rank_color, overall_rank_score,
+            language_ranks, total_authored, total_completed, created_at, updated_at
+        )
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+        ON CONFLICT (username) DO UPDATE SET
+            honor = EXCLUDED.honor,
+            codewars_id = EXCLUDED.codewars_id,
+            name = EXCLUDED.name,
+            clan = EXCLUDED.clan,
+            leaderboard_position = EXCLUDED.leaderboard_position,
+            skills = EXCLUDED.skills,
+            overall_rank = EXCLUDED.overall_rank,
+            overall_rank_name = EXCLUDED.overall_rank_name,
+            overall_rank_color = EXCLUDED.overall_rank_color,
+            overall_rank_score = EXCLUDED.overall_rank_score,
+            language_ranks = EXCLUDED.language_ranks,
+            total_authored = EXCLUDED.total_authored,
+            total_completed = EXCLUDED.total_completed,
+            updated_at = EXCLUDED.updated_at
     `
-	_, err := r.db.ExecContext(ctx, query, user.Username, user.Honor)
+	_, err := r.db.ExecContext(ctx, query,
+		user.Username,
+		user.Honor,
+		user.ID,
+		user.Name,
+		user.Clan,
+		user.LeaderboardPosition,
+		skillsJSON,
+		user.Ranks.Overall.Rank,
+		user.Ranks.Overall.Name,
+		user.Ranks.Overall.Color,
+		user.Ranks.Overall.Score,
+		languageRanksJSON,
+		user.CodeChallenges.TotalAuthored,
+		user.CodeChallenges.TotalCompleted,
+		user.CreatedAt.UTC(),
+		user.SyncedAt.UTC(),
+	)
 	return err
 }
 
-func (r *UserRepo) GetUser(ctx context.Context, username string) (*model.User, error) {
-	query := `SELECT username, honor, created_at FROM users WHERE username = $1`
-	row := r.db.QueryRowContext(ctx, query, username)
+// userColumns - колонки профиля в порядке, который ожидает scanUser
+const userColumns = `
+        username, honor, codewars_id, name, clan, leaderboard_position, skills,
+        overall_rank, overall_rank_name, overall_rank_color, overall_rank_score,
+        language_ranks, total_authored, total_completed, created_at, updated_at`
+
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+func scanUser(row rowScanner) (*model.User, error) {
 	var user model.User
-	err := row.Scan(&user.Username, &user.Honor, &user.CreatedAt)
+	var leaderboardPosition sql.NullInt64
+	var skillsJSON, languageRanksJSON []byte
+
+	err := row.Scan(
+		&user.Username,
+		&user.Honor,
+		&user.ID,
+		&user.Name,
+		&user.Clan,
+		&leaderboardPosition,
+		&skillsJSON,
+		&user.Ranks.Overall.Rank,
+		&user.Ranks.Overall.Name,
+		&user.Ranks.Overall.Color,
+		&user.Ranks.Overall.Score,
+		&languageRanksJSON,
+		&user.CodeChallenges.TotalAuthored,
+		&user.CodeChallenges.TotalCompleted,
+		&user.CreatedAt,
+		&user.SyncedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if leaderboardPosition.Valid {
+		pos := int(leaderboardPosition.Int64)
+		user.LeaderboardPosition = &pos
+	}
+	json.Unmarshal(skillsJSON, &user.Skills)
+	json.Unmarshal(languageRanksJSON, &user.Ranks.Languages)
+
+	return &user, nil
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, username string) (*model.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM users
+        WHERE username = $1
+    `
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, username))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, repository.ErrUserNotFound
@@ -39,5 +130,138 @@ func (r *UserRepo) GetUser(ctx context.Context, username string) (*model.User, e
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// GetUsersByClan возвращает известных пользователей клана, по убыванию хонора
+func (r *UserRepo) GetUsersByClan(ctx context.Context, clan string) ([]model.User, error) {
+	query := `SELECT` + userColumns + `
+        FROM users
+        WHERE clan = $1
+        ORDER BY honor DESC, username
+    `
+	rows, err := r.db.QueryContext(ctx, query, clan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clan users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+// userSortColumns - колонки ORDER BY для каждой сортировки; username делает порядок однозначным
+var userSortColumns = map[model.UserSort][]string{
+	model.SortByHonor:     {"honor", "username"},
+	model.SortByRank:      {rankedKey, "overall_rank", "overall_rank_score", "username"},
+	model.SortByUsername:  {"username"},
+	model.SortByUpdatedAt: {"updated_at", "username"},
+}
+
+// rankedKey - ведущий ключ сортировки по рангу. Ранг 0 (нет ранга) иначе
+// оказался бы между 1 kyu (-1) и 1 dan (1); ключ ставит таких пользователей
+// в конец при любом направлении
+const rankedKey = "ranked"
+
+// sortExpr возвращает выражение SQL для ключа сортировки. Выражения для
+// rankedKey совпадают с индексами idx_users_overall_rank_desc и _asc
+func sortExpr(col string, desc bool) string {
+	if col != rankedKey {
+		return col
+	}
+	if desc {
+		return "(overall_rank <> 0)"
+	}
+	return "(overall_rank = 0)"
+}
+
+func (r *UserRepo) ListUsers(ctx context.Context, q model.UserQuery) ([]model.User, error) {
+	columns, ok := userSortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", q.Sort)
+	}
+
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Clan != "" {
+		conds = append(conds, "clan = "+arg(q.Clan))
+	}
+	if q.MinRank != 0 {
+		conds = append(conds, "overall_rank <> 0", "overall_rank >= "+arg(q.MinRank))
+	}
+	if q.Language != "" {
+		conds = append(conds, "language_ranks ? "+arg(q.Language))
+	}
+	if !q.SyncedSince.IsZero() {
+		conds = append(conds, "updated_at >= "+arg(q.SyncedSince.UTC()))
+	}
+
+	// Keyset-пагинация: строки строго после курсора в порядке сортировки
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if c := q.After; c != nil {
+		values := map[string]any{
+			rankedKey:            (c.Rank != 0) == q.Desc,
+			"honor":              c.Honor,
+			"overall_rank":       c.Rank,
+			"overall_rank_score": c.RankScore,
+			"updated_at":         c.UpdatedAt.UTC(),
+			"username":           c.Username,
+		}
+		exprs := make([]string, len(columns))
+		placeholders := make([]string, len(columns))
+		for i, col := range columns {
+			exprs[i] = sortExpr(col, q.Desc)
+			placeholders[i] = arg(values[col])
+		}
+		conds = append(conds, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(exprs, ", "), cmp, strings.Join(placeholders, ", ")))
+	}
+
+	order := make([]string, len(columns))
+	for i, col := range columns {
+		order[i] = sortExpr(col, q.Desc) + " " + dir
+	}
+
+	query := `SELECT` + userColumns + `
+        FROM users`
+	if len(conds) > 0 {
+		query += `
+        WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += `
+        ORDER BY ` + strings.Join(order, ", ") + `
+        LIMIT ` + arg(q.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
 }
@@ -4,19 +4,77 @@ import (
 	"SolverAPI/internal/model"
 	"context"
 	"errors"
+	"time"
 )
 
 // UserRepository определяет контракт для работы с пользователями
 type UserRepository interface {
 	CreateOrUpdateUser(ctx context.Context, user *model.User) error
 	GetUser(ctx context.Context, username string) (*model.User, error)
+	GetUsersByClan(ctx context.Context, clan string) ([]model.User, error)
+	// ListUsers возвращает страницу пользователей по фильтрам и сортировке запроса
+	ListUsers(ctx context.Context, query model.UserQuery) ([]model.User, error)
+}
+
+// CompletedChallengeRepository хранит решенные пользователями задачи
+type CompletedChallengeRepository interface {
+	SaveCompletedChallenges(ctx context.Context, username string, challenges []model.CompletedChallenge) error
+	GetCompletedChallenges(ctx context.Context, username string) ([]model.CompletedChallenge, error)
+}
+
+// AuthoredChallengeRepository хранит задачи, созданные пользователями
+type AuthoredChallengeRepository interface {
+	SaveAuthoredChallenges(ctx context.Context, username string, challenges []model.AuthoredChallenge) error
+	GetAuthoredChallenges(ctx context.Context, username string) ([]model.AuthoredChallenge, error)
+}
+
+// UserSnapshotRepository хранит историю хонора и рангов пользователей
+type UserSnapshotRepository interface {
+	// AppendUserSnapshot добавляет снимок, только если он отличается от последнего.
+	// Возвращает true, если снимок добавлен
+	AppendUserSnapshot(ctx context.Context, snapshot model.UserSnapshot) (bool, error)
+	// GetUserSnapshots возвращает снимки за [from, to] по возрастанию времени
+	// и последний снимок перед from, если он есть
+	GetUserSnapshots(ctx context.Context, username string, from, to time.Time) ([]model.UserSnapshot, error)
+	// GetUserSnapshotsAt возвращает для каждого пользователя последний снимок
+	// не позже at, а если таких нет - самый ранний после at.
+	// usernames == nil - все пользователи
+	GetUserSnapshotsAt(ctx context.Context, usernames []string, at time.Time) ([]model.UserSnapshot, error)
+}
+
+// TrackedUserRepository - реестр пользователей для фоновой синхронизации
+type TrackedUserRepository interface {
+	// TrackUser добавляет пользователя в реестр, в том числе после отказа
+	// от отслеживания. Возвращает false, если он уже там
+	TrackUser(ctx context.Context, username, source string) (bool, error)
+	// TrackClan добавляет в реестр известных пользователей клана, кроме except
+	// и отказавшихся от отслеживания. Возвращает число добавленных
+	TrackClan(ctx context.Context, clan, except string) (int, error)
+	// UntrackUser убирает пользователя из реестра и запоминает отказ
+	UntrackUser(ctx context.Context, username string) error
+	GetTrackedUser(ctx context.Context, username string) (*model.TrackedUser, error)
+	ListTrackedUsers(ctx context.Context) ([]model.TrackedUser, error)
+	// RecordSyncResult сохраняет результат синхронизации; syncErr == nil - успех
+	RecordSyncResult(ctx context.Context, username string, at time.Time, syncErr error) error
 }
 
 type KataRepository interface {
 	SaveKata(ctx context.Context, kata *model.Kata) error
-	GetRandomKata(ctx context.Context) (*model.Kata, error)
+	// GetRandomKata возвращает случайную сохраненную задачу, подходящую под фильтр
+	GetRandomKata(ctx context.Context, filter model.KataFilter) (*model.Kata, error)
+}
+
+// KataBufferRepository хранит буфер ID задач между перезапусками
+type KataBufferRepository interface {
+	LoadKataBuffer(ctx context.Context) ([]model.KataBufferEntry, error)
+	// MergeKataBuffer добавляет найденные ID к буферу фильтра, обновляет
+	// время последнего появления и удаляет ID, не встречавшиеся с staleBefore
+	MergeKataBuffer(ctx context.Context, filter model.KataFilter, filterKey string, ids []string, seenAt, staleBefore time.Time) error
 }
 
 var (
 	ErrUserNotFound = errors.New("user not found")
+	ErrClanNotFound = errors.New("clan not found")
+	ErrNotTracked   = errors.New("user is not tracked")
+	ErrKataNotFound = errors.New("kata not found")
 )
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// GetClan возвращает известных пользователей клана и сводные показатели по ним
+func (s *UserService) GetClan(ctx context.Context, name string) (*model.Clan, error) {
+	users, err := s.repo.GetUsersByClan(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clan users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, repository.ErrClanNotFound
+	}
+
+	clan := &model.Clan{
+		Name:        name,
+		MemberCount: len(users),
+		Users:       users,
+	}
+
+	var rankSum, ranked int
+	for _, user := range users {
+		clan.TotalHonor += user.Honor
+		if rank := user.Ranks.Overall.Rank; rank != 0 {
+			rankSum += rankIndex(rank)
+			ranked++
+		}
+	}
+	if ranked > 0 {
+		avg := int(math.Round(float64(rankSum) / float64(ranked)))
+		clan.AverageRank = rankFromIndex(avg)
+		clan.AverageRankName = model.RankName(clan.AverageRank)
+	}
+
+	return clan, nil
+}
+
+// rankIndex переводит ранг Codewars (-8..-1, 1..8) в непрерывную шкалу 0..15,
+// чтобы среднее между 1 kyu и 1 dan не попадало в несуществующий ранг 0
+func rankIndex(rank int) int {
+	if rank < 0 {
+		return rank + 8
+	}
+	return rank + 7
+}
+
+func rankFromIndex(index int) int {
+	if index < 8 {
+		return index - 8
+	}
+	return index - 7
+}
+
+// clanTracker добавляет известных соклановцев в реестр отслеживаемых
+// не чаще раза в minInterval для каждого клана
+type clanTracker struct {
+	tracked     repository.TrackedUserRepository
+	minInterval time.Duration
+
+	mu      sync.Mutex
+	lastRun map[string]time.Time // Последнее добавление соклановцев по кланам
+}
+
+// EnableClanAutoTrack включает автоматическое отслеживание соклановцев:
+// после синхронизации пользователя уже известные участники его клана
+// добавляются в реестр, и дальше их обновляет SyncScheduler.
+// Для одного клана это делается не чаще раза в minInterval
+func (s *UserService) EnableClanAutoTrack(tracked repository.TrackedUserRepository, minInterval time.Duration) {
+	s.clans = &clanTracker{
+		tracked:     tracked,
+		minInterval: minInterval,
+		lastRun:     make(map[string]time.Time),
+	}
+}
+
+// due отмечает запуск для клана, если с прошлого прошло не меньше minInterval
+func (t *clanTracker) due(clan string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if last, ok := t.lastRun[clan]; ok && now.Sub(last) < t.minInterval {
+		return false
+	}
+	t.lastRun[clan] = now
+	return true
+}
+
+// forget снимает отметку, чтобы после ошибки клан обработался при следующей синхронизации
+func (t *clanTracker) forget(clan string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.lastRun, clan)
+}
+
+// trackClan добавляет соклановцев user в реестр. Ошибка не прерывает синхронизацию
+func (s *UserService) trackClan(ctx context.Context, user *model.User) {
+	t := s.clans
+	if t == nil || user.Clan == "" || !t.due(user.Clan, time.Now()) {
+		return
+	}
+
+	n, err := t.tracked.TrackClan(ctx, user.Clan, user.Username)
+	if err != nil {
+		t.forget(user.Clan)
+		slog.Warn("failed to track clanmates", "clan", user.Clan, "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("tracking new clanmates", "clan", user.Clan, "count", n)
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/pkg/codewars"
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// staleReadTimeout ограничивает чтение сохраненных данных после сбоя Codewars
+const staleReadTimeout = 5 * time.Second
+
+// CodewarsUsers - источник данных о пользователях Codewars.
+// Реализуется *codewars.Client, но может быть обернут кэшем, метриками или заменен фейком
+type CodewarsUsers interface {
+	GetUser(ctx context.Context, username string) (*model.CodewarsUser, error)
+	CompletedChallenges(ctx context.Context, username string) iter.Seq2[model.CompletedChallenge, error]
+	AuthoredChallenges(ctx context.Context, username string) ([]model.AuthoredChallenge, error)
+}
+
+// CodewarsKatas - источник задач Codewars
+type CodewarsKatas interface {
+	GetKataByID(ctx context.Context, id string) (*model.CodewarsKata, error)
+	GetRandomKataIDMatching(ctx context.Context, filter model.KataFilter) (string, error)
+	SearchKatas(ctx context.Context, filter model.KataFilter, limit int) ([]model.KataSummary, error)
+	RefreshBuffer()
+}
+
+// isUpstreamFailure сообщает, что Codewars не ответил по существу: недоступен,
+// открыт breaker, превышен лимит запросов или истек таймаут.
+// В этих случаях сервисы отдают сохраненные в БД данные. ErrNotFound
+// и codewars.ErrFixtureNotFound (нет записи в режиме replay) сюда не входят
+func isUpstreamFailure(err error) bool {
+	return errors.Is(err, codewars.ErrUnavailable) ||
+		errors.Is(err, codewars.ErrCircuitOpen) ||
+		errors.Is(err, codewars.ErrRateLimited) ||
+		errors.Is(err, codewars.ErrDecode) ||
+		errors.Is(err, codewars.ErrBufferEmpty) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// staleContext - контекст чтения сохраненных данных после сбоя Codewars.
+// Дедлайн запроса к этому моменту мог уже истечь (ошибка - его таймаут),
+// поэтому чтение из БД получает собственный короткий таймаут
+func staleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), staleReadTimeout)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// fakeUserRepo хранит пользователей в памяти
+type fakeUserRepo struct {
+	mu    sync.Mutex
+	users map[string]model.User
+}
+
+func newFakeUserRepo(users ...model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[string]model.User)}
+	for _, u := range users {
+		r.users[u.Username] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) CreateOrUpdateUser(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.Username] = *user
+	return nil
+}
+
+func (r *fakeUserRepo) GetUser(ctx context.Context, username string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *fakeUserRepo) GetUsersByClan(ctx context.Context, clan string) ([]model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []model.User
+	for _, u := range r.users {
+		if u.Clan == clan {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeUserRepo) ListUsers(ctx context.Context, query model.UserQuery) ([]model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Collect(maps.Values(r.users)), nil
+}
+
+// fakeSnapshotRepo повторяет семантику postgres.UserSnapshotRepo:
+// снимок, совпадающий с последним, не добавляется
+type fakeSnapshotRepo struct {
+	mu        sync.Mutex
+	snapshots []model.UserSnapshot // по возрастанию TakenAt
+}
+
+func (r *fakeSnapshotRepo) AppendUserSnapshot(ctx context.Context, s model.UserSnapshot) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.snapshots) - 1; i >= 0; i-- {
+		last := r.snapshots[i]
+		if last.Username != s.Username {
+			continue
+		}
+		if last.Honor == s.Honor && last.OverallRank.Rank == s.OverallRank.Rank &&
+			last.OverallRank.Score == s.OverallRank.Score && last.TotalCompleted == s.TotalCompleted &&
+			reflect.DeepEqual(last.LanguageRanks, s.LanguageRanks) {
+			return false, nil
+		}
+		break
+	}
+	r.snapshots = append(r.snapshots, s)
+	return true, nil
+}
+
+func (r *fakeSnapshotRepo) GetUserSnapshots(ctx context.Context, username string, from, to time.Time) ([]model.UserSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var before *model.UserSnapshot
+	var result []model.UserSnapshot
+	for i, s := range r.snapshots {
+		switch {
+		case s.Username != username || s.TakenAt.After(to):
+		case s.TakenAt.Before(from):
+			before = &r.snapshots[i]
+		default:
+			result = append(result, s)
+		}
+	}
+	if before != nil {
+		result = append([]model.UserSnapshot{*before}, result...)
+	}
+	return result, nil
+}
+
+func (r *fakeSnapshotRepo) GetUserSnapshotsAt(ctx context.Context, usernames []string, at time.Time) ([]model.UserSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := make(map[string]model.UserSnapshot)
+	for _, s := range r.snapshots {
+		if usernames != nil && !slices.Contains(usernames, s.Username) {
+			continue
+		}
+		// Снимки идут по возрастанию: первый после at остается,
+		// только если раньше at снимков нет
+		if _, ok := found[s.Username]; !ok || !s.TakenAt.After(at) {
+			found[s.Username] = s
+		}
+	}
+	return slices.Collect(maps.Values(found)), nil
+}
+
+// fakeTrackedRepo - реестр в памяти; listed получает сигнал на каждый ListTrackedUsers
+type fakeTrackedRepo struct {
+	mu     sync.Mutex
+	users  []model.TrackedUser
+	listed chan struct{}
+}
+
+func (r *fakeTrackedRepo) TrackUser(ctx context.Context, username, source string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == username {
+			return false, nil
+		}
+	}
+	r.users = append(r.users, model.TrackedUser{Username: username, Source: source, AddedAt: time.Now().UTC()})
+	return true, nil
+}
+
+func (r *fakeTrackedRepo) TrackClan(ctx context.Context, clan, except string) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeTrackedRepo) UntrackUser(ctx context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, u := range r.users {
+		if u.Username == username {
+			r.users = slices.Delete(r.users, i, i+1)
+			return nil
+		}
+	}
+	return repository.ErrNotTracked
+}
+
+func (r *fakeTrackedRepo) GetTrackedUser(ctx context.Context, username string) (*model.TrackedUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+	return nil, repository.ErrNotTracked
+}
+
+func (r *fakeTrackedRepo) ListTrackedUsers(ctx context.Context) ([]model.TrackedUser, error) {
+	r.mu.Lock()
+	users := slices.Clone(r.users)
+	r.mu.Unlock()
+
+	if r.listed != nil {
+		r.listed <- struct{}{}
+	}
+	return users, nil
+}
+
+func (r *fakeTrackedRepo) RecordSyncResult(ctx context.Context, username string, at time.Time, syncErr error) error {
+	return nil
+}
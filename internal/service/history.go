@@ -0,0 +1,64 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"fmt"
+	"time"
+)
+
+// UserHistory возвращает ряд хонора и рангов пользователя за [from, to)
+// с шагом resolution. Значение точки - последний снимок к концу периода;
+// периоды до первого снимка пропускаются
+func (s *UserService) UserHistory(ctx context.Context, username string, from, to time.Time, resolution model.HistoryResolution) ([]model.HistoryPoint, error) {
+	if _, err := s.repo.GetUser(ctx, username); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.snapshots.GetUserSnapshots(ctx, username, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user history: %w", err)
+	}
+
+	points := []model.HistoryPoint{}
+	var last *model.UserSnapshot
+	next := 0
+	for start := truncatePeriod(from, resolution); start.Before(to); start = nextPeriod(start, resolution) {
+		end := nextPeriod(start, resolution)
+		for next < len(snapshots) && snapshots[next].TakenAt.Before(end) {
+			last = &snapshots[next]
+			next++
+		}
+		if last == nil {
+			continue
+		}
+
+		points = append(points, model.HistoryPoint{
+			Start:          start,
+			Honor:          last.Honor,
+			OverallRank:    last.OverallRank,
+			LanguageRanks:  last.LanguageRanks,
+			TotalCompleted: last.TotalCompleted,
+		})
+	}
+
+	return points, nil
+}
+
+// truncatePeriod возвращает начало периода (UTC), в который попадает t.
+// Неделя начинается в понедельник
+func truncatePeriod(t time.Time, resolution model.HistoryResolution) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if resolution == model.ResolutionWeek {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+func nextPeriod(start time.Time, resolution model.HistoryResolution) time.Time {
+	if resolution == model.ResolutionWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
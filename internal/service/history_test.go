@@ -0,0 +1,127 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUserHistory(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC) }
+	snap := func(t time.Time, honor int) model.UserSnapshot {
+		return model.UserSnapshot{Username: "some_user", Honor: honor, TakenAt: t}
+	}
+
+	// 1 января 2024 - понедельник
+	history := []model.UserSnapshot{
+		snap(at(1, 9).AddDate(0, 0, -2), 90),
+		snap(at(1, 9), 100),
+		snap(at(1, 18), 110),
+		snap(at(3, 12), 130),
+		snap(at(9, 0), 150),
+	}
+
+	type point struct {
+		start time.Time
+		honor int
+	}
+
+	tests := []struct {
+		name       string
+		snapshots  []model.UserSnapshot
+		from, to   time.Time
+		resolution model.HistoryResolution
+		want       []point
+	}{
+		{
+			name:       "last snapshot of each day, gaps carried over",
+			snapshots:  history,
+			from:       at(1, 0),
+			to:         at(4, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{{at(1, 0), 110}, {at(2, 0), 110}, {at(3, 0), 130}},
+		},
+		{
+			name:       "snapshot before from sets the first value",
+			snapshots:  history,
+			from:       at(2, 0),
+			to:         at(3, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{{at(2, 0), 110}},
+		},
+		{
+			name:       "periods before the first snapshot are skipped",
+			snapshots:  history[1:],
+			from:       at(1, 0).AddDate(0, 0, -2),
+			to:         at(2, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{{at(1, 0), 110}},
+		},
+		{
+			name:       "from is truncated to the start of its day",
+			snapshots:  history,
+			from:       at(1, 12),
+			to:         at(2, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{{at(1, 0), 110}},
+		},
+		{
+			name:       "weeks start on monday",
+			snapshots:  history,
+			from:       at(3, 0),
+			to:         at(15, 0),
+			resolution: model.ResolutionWeek,
+			want:       []point{{at(1, 0), 130}, {at(8, 0), 150}},
+		},
+		{
+			name:       "periods are in UTC",
+			snapshots:  history,
+			from:       time.Date(2024, 1, 1, 2, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			to:         at(2, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{{at(1, 0).AddDate(0, 0, -1), 90}, {at(1, 0), 110}},
+		},
+		{
+			name:       "no snapshots",
+			from:       at(1, 0),
+			to:         at(4, 0),
+			resolution: model.ResolutionDay,
+			want:       []point{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepo(model.User{CodewarsUser: model.CodewarsUser{Username: "some_user"}})
+			snapshots := &fakeSnapshotRepo{snapshots: tt.snapshots}
+			svc := NewUserService(users, nil, nil, snapshots, nil)
+
+			points, err := svc.UserHistory(context.Background(), "some_user", tt.from, tt.to, tt.resolution)
+			if err != nil {
+				t.Fatalf("UserHistory: %v", err)
+			}
+
+			got := []point{}
+			for _, p := range points {
+				got = append(got, point{p.Start, p.Honor})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("points = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserHistoryUnknownUser(t *testing.T) {
+	svc := NewUserService(newFakeUserRepo(), nil, nil, &fakeSnapshotRepo{}, nil)
+
+	now := time.Now().UTC()
+	_, err := svc.UserHistory(context.Background(), "nobody", now.AddDate(0, 0, -7), now, model.ResolutionDay)
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("err = %v, want ErrUserNotFound", err)
+	}
+}
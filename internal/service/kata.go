@@ -3,7 +3,6 @@ package service
 import (
 	"SolverAPI/internal/model"
 	"SolverAPI/internal/repository"
-	"SolverAPI/pkg/codewars"
 	"fmt"
 
 	"context"
@@ -12,36 +11,47 @@ import (
 
 type KataService struct {
 	repo     repository.KataRepository
-	cwClient *codewars.Client
+	cwClient CodewarsKatas
 }
 
-func NewKataService(repo repository.KataRepository, cwClient *codewars.Client) *KataService {
+func NewKataService(repo repository.KataRepository, cwClient CodewarsKatas) *KataService {
 	return &KataService{
 		repo:     repo,
 		cwClient: cwClient,
 	}
 }
 
-func (s *KataService) GetRandomKata(ctx context.Context) (*model.Kata, error) {
-	//Обновляем буфер при необходимости
+// GetRandomKata возвращает случайную задачу, подходящую под фильтр
+// (пустой фильтр - любая задача)
+func (s *KataService) GetRandomKata(ctx context.Context, filter model.KataFilter) (*model.Kata, error) {
+	//Обновляем буфер при необходимости (в фоне, не блокируя запрос)
 	s.cwClient.RefreshBuffer()
 
 	//Получаем случайный ID из буфера
-	randID, err := s.cwClient.GetRandomKataID(ctx)
+	randID, err := s.cwClient.GetRandomKataIDMatching(ctx, filter)
 	if err != nil {
+		//Codewars недоступен - берем одну из ранее сохраненных задач
+		if kata, ok := s.staleKata(ctx, filter, err); ok {
+			return kata, nil
+		}
 		return nil, fmt.Errorf("failed to get random ID: %w", err)
 	}
 
 	//Получаем полные данные по задаче
 	cwKata, err := s.cwClient.GetKataByID(ctx, randID)
 	if err != nil {
+		if kata, ok := s.staleKata(ctx, filter, err); ok {
+			return kata, nil
+		}
 		return nil, fmt.Errorf("failed to get kata details: %w", err)
 	}
 
 	//Сохраняем в БД
+	now := time.Now().UTC()
 	kata := &model.Kata{
 		CodewarsKata: *cwKata,
-		AddedAt:      time.Now(),
+		AddedAt:      now,
+		SyncedAt:     now,
 	}
 
 	if err := s.repo.SaveKata(ctx, kata); err != nil {
@@ -50,3 +60,31 @@ func (s *KataService) GetRandomKata(ctx context.Context) (*model.Kata, error) {
 
 	return kata, nil
 }
+
+// staleKata возвращает случайную задачу из БД, подходящую под фильтр,
+// если ошибка Codewars временная
+func (s *KataService) staleKata(ctx context.Context, filter model.KataFilter, cwErr error) (*model.Kata, bool) {
+	if !isUpstreamFailure(cwErr) {
+		return nil, false
+	}
+
+	ctx, cancel := staleContext(ctx)
+	defer cancel()
+
+	kata, err := s.repo.GetRandomKata(ctx, filter)
+	if err != nil {
+		return nil, false
+	}
+	kata.Stale = true
+
+	return kata, true
+}
+
+// SearchKatas ищет задачи на Codewars по фильтру
+func (s *KataService) SearchKatas(ctx context.Context, filter model.KataFilter, limit int) ([]model.KataSummary, error) {
+	katas, err := s.cwClient.SearchKatas(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search katas: %w", err)
+	}
+	return katas, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LeaderboardService строит рейтинги пользователей по истории снимков
+type LeaderboardService struct {
+	snapshots repository.UserSnapshotRepository
+	tracked   repository.TrackedUserRepository
+}
+
+func NewLeaderboardService(snapshots repository.UserSnapshotRepository, tracked repository.TrackedUserRepository) *LeaderboardService {
+	return &LeaderboardService{snapshots: snapshots, tracked: tracked}
+}
+
+// leaderboardValue - значение показателя: основное и второстепенное для равных основных
+type leaderboardValue struct {
+	primary   int
+	secondary int
+	rankName  string
+}
+
+// Leaderboard строит рейтинг за [From, To] и сравнивает его с рейтингом
+// за предыдущий период [PreviousFrom, From]
+func (s *LeaderboardService) Leaderboard(ctx context.Context, q model.LeaderboardQuery) (*model.Leaderboard, error) {
+	board := &model.Leaderboard{
+		Metric:       q.Metric,
+		Language:     q.Language,
+		From:         q.From,
+		To:           q.To,
+		PreviousFrom: q.PreviousFrom,
+		Entries:      []model.LeaderboardEntry{},
+	}
+
+	var usernames []string
+	if !q.AllUsers {
+		tracked, err := s.tracked.ListTrackedUsers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracked users: %w", err)
+		}
+		if len(tracked) == 0 {
+			return board, nil
+		}
+		usernames = make([]string, len(tracked))
+		for i, user := range tracked {
+			usernames[i] = user.Username
+		}
+	}
+
+	// Состояние пользователей на границах текущего и предыдущего периодов
+	var states [3]map[string]model.UserSnapshot
+	for i, at := range []time.Time{q.PreviousFrom, q.From, q.To} {
+		snapshots, err := s.snapshots.GetUserSnapshotsAt(ctx, usernames, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaderboard snapshots: %w", err)
+		}
+		states[i] = make(map[string]model.UserSnapshot, len(snapshots))
+		for _, snapshot := range snapshots {
+			states[i][snapshot.Username] = snapshot
+		}
+	}
+
+	current := rankLeaderboard(leaderboardValues(q, states[1], states[2], q.To))
+	previous := rankLeaderboard(leaderboardValues(q, states[0], states[1], q.From))
+
+	prevPositions := make(map[string]int, len(previous))
+	for _, entry := range previous {
+		prevPositions[entry.Username] = entry.Position
+	}
+	for i := range current {
+		if pos, ok := prevPositions[current[i].Username]; ok {
+			change := pos - current[i].Position
+			current[i].PreviousPosition = &pos
+			current[i].Change = &change
+		}
+	}
+
+	board.Entries = current
+	return board, nil
+}
+
+// leaderboardValues считает показатель за период по состояниям на его начало
+// и конец. Пользователи без снимка к концу периода в рейтинг не попадают
+func leaderboardValues(q model.LeaderboardQuery, start, end map[string]model.UserSnapshot, endAt time.Time) map[string]leaderboardValue {
+	values := make(map[string]leaderboardValue, len(end))
+	for username, last := range end {
+		if last.TakenAt.After(endAt) {
+			continue
+		}
+
+		switch q.Metric {
+		case model.MetricHonor:
+			values[username] = leaderboardValue{primary: last.Honor}
+		case model.MetricRank:
+			if last.OverallRank.Rank == 0 {
+				continue
+			}
+			values[username] = leaderboardValue{
+				primary:   last.OverallRank.Rank,
+				secondary: last.OverallRank.Score,
+				rankName:  last.OverallRank.Name,
+			}
+		case model.MetricLanguage:
+			rank, ok := last.LanguageRanks[q.Language]
+			if !ok || rank.Rank == 0 {
+				continue
+			}
+			values[username] = leaderboardValue{primary: rank.Rank, secondary: rank.Score, rankName: rank.Name}
+		case model.MetricHonorGain, model.MetricCompletions:
+			// Без снимка на начало периода отсчитываем от первого снимка в периоде
+			first, ok := start[username]
+			if !ok {
+				first = last
+			}
+			if q.Metric == model.MetricHonorGain {
+				values[username] = leaderboardValue{primary: last.Honor - first.Honor}
+			} else {
+				values[username] = leaderboardValue{primary: last.TotalCompleted - first.TotalCompleted}
+			}
+		}
+	}
+	return values
+}
+
+// rankLeaderboard сортирует значения по убыванию и расставляет места:
+// равные значения делят место, следующее место пропускается (1, 2, 2, 4)
+func rankLeaderboard(values map[string]leaderboardValue) []model.LeaderboardEntry {
+	entries := make([]model.LeaderboardEntry, 0, len(values))
+	for username, v := range values {
+		entry := model.LeaderboardEntry{Username: username, Value: v.primary, RankName: v.rankName}
+		if v.rankName != "" {
+			entry.Score = v.secondary
+		}
+		entries = append(entries, entry)
+	}
+
+	// По убыванию значения; при равенстве - по имени, чтобы порядок был стабильным
+	byValue := func(a, b model.LeaderboardEntry) int {
+		va, vb := values[a.Username], values[b.Username]
+		if c := cmp.Compare(vb.primary, va.primary); c != 0 {
+			return c
+		}
+		return cmp.Compare(vb.secondary, va.secondary)
+	}
+	slices.SortFunc(entries, func(a, b model.LeaderboardEntry) int {
+		if c := byValue(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Username, b.Username)
+	})
+
+	for i := range entries {
+		if i > 0 && byValue(entries[i-1], entries[i]) == 0 {
+			entries[i].Position = entries[i-1].Position
+		} else {
+			entries[i].Position = i + 1
+		}
+	}
+	return entries
+}
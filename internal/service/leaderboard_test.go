@@ -0,0 +1,173 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRankLeaderboard(t *testing.T) {
+	type entry struct {
+		username string
+		position int
+		value    int
+		score    int
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]leaderboardValue
+		want   []entry
+	}{
+		{
+			name:   "descending values",
+			values: map[string]leaderboardValue{"a": {primary: 10}, "b": {primary: 30}, "c": {primary: 20}},
+			want:   []entry{{"b", 1, 30, 0}, {"c", 2, 20, 0}, {"a", 3, 10, 0}},
+		},
+		{
+			name:   "ties share a position and skip the next",
+			values: map[string]leaderboardValue{"d": {primary: 5}, "c": {primary: 7}, "b": {primary: 7}, "a": {primary: 9}},
+			want:   []entry{{"a", 1, 9, 0}, {"b", 2, 7, 0}, {"c", 2, 7, 0}, {"d", 4, 5, 0}},
+		},
+		{
+			name: "secondary value breaks ties",
+			values: map[string]leaderboardValue{
+				"a": {primary: -4, secondary: 100, rankName: "4 kyu"},
+				"b": {primary: -4, secondary: 300, rankName: "4 kyu"},
+				"c": {primary: -2, secondary: 0, rankName: "2 kyu"},
+			},
+			want: []entry{{"c", 1, -2, 0}, {"b", 2, -4, 300}, {"a", 3, -4, 100}},
+		},
+		{
+			name: "equal primary and secondary share a position",
+			values: map[string]leaderboardValue{
+				"b": {primary: -4, secondary: 100, rankName: "4 kyu"},
+				"a": {primary: -4, secondary: 100, rankName: "4 kyu"},
+				"c": {primary: -5, secondary: 900, rankName: "5 kyu"},
+			},
+			want: []entry{{"a", 1, -4, 100}, {"b", 1, -4, 100}, {"c", 3, -5, 900}},
+		},
+		{
+			name:   "negative gains rank last",
+			values: map[string]leaderboardValue{"a": {primary: -10}, "b": {primary: 0}, "c": {primary: 3}},
+			want:   []entry{{"c", 1, 3, 0}, {"b", 2, 0, 0}, {"a", 3, -10, 0}},
+		},
+		{
+			name:   "empty",
+			values: map[string]leaderboardValue{},
+			want:   []entry{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []entry{}
+			for _, e := range rankLeaderboard(tt.values) {
+				got = append(got, entry{e.Username, e.Position, e.Value, e.Score})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardPositionChange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	snap := func(username string, d, honor int) model.UserSnapshot {
+		return model.UserSnapshot{Username: username, Honor: honor, TakenAt: day(d)}
+	}
+
+	// Предыдущий период - 1..8 января, текущий - 8..15
+	snapshots := &fakeSnapshotRepo{snapshots: []model.UserSnapshot{
+		snap("alice", 1, 100), snap("bob", 1, 100), snap("carol", 1, 100), snap("eve", 1, 0),
+		snap("alice", 8, 200), snap("bob", 8, 150), snap("carol", 8, 100),
+		snap("dave", 10, 50),
+		snap("alice", 15, 210), snap("bob", 15, 300), snap("carol", 15, 150), snap("dave", 15, 80), snap("eve", 15, 1000),
+	}}
+	tracked := &fakeTrackedRepo{users: []model.TrackedUser{
+		{Username: "alice"}, {Username: "bob"}, {Username: "carol"}, {Username: "dave"},
+	}}
+	svc := NewLeaderboardService(snapshots, tracked)
+
+	// previous == 0 - пользователя не было в предыдущем рейтинге
+	type entry struct {
+		username string
+		position int
+		value    int
+		previous int
+	}
+
+	tests := []struct {
+		name     string
+		allUsers bool
+		want     []entry
+	}{
+		{
+			name: "tracked users",
+			want: []entry{
+				{"bob", 1, 150, 2},
+				{"carol", 2, 50, 3},
+				{"dave", 3, 30, 0},
+				{"alice", 4, 10, 1},
+			},
+		},
+		{
+			name:     "all users",
+			allUsers: true,
+			want: []entry{
+				{"eve", 1, 1000, 3},
+				{"bob", 2, 150, 2},
+				{"carol", 3, 50, 3},
+				{"dave", 4, 30, 0},
+				{"alice", 5, 10, 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := svc.Leaderboard(context.Background(), model.LeaderboardQuery{
+				Metric:       model.MetricHonorGain,
+				PreviousFrom: day(1),
+				From:         day(8),
+				To:           day(15),
+				AllUsers:     tt.allUsers,
+			})
+			if err != nil {
+				t.Fatalf("Leaderboard: %v", err)
+			}
+
+			got := []entry{}
+			for _, e := range board.Entries {
+				var previous int
+				if e.PreviousPosition != nil {
+					previous = *e.PreviousPosition
+					if e.Change == nil || *e.Change != previous-e.Position {
+						t.Errorf("%s: change = %v, want %d", e.Username, e.Change, previous-e.Position)
+					}
+				} else if e.Change != nil {
+					t.Errorf("%s: change = %d without previous position", e.Username, *e.Change)
+				}
+				got = append(got, entry{e.Username, e.Position, e.Value, previous})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardWithoutTrackedUsers(t *testing.T) {
+	svc := NewLeaderboardService(&fakeSnapshotRepo{}, &fakeTrackedRepo{})
+
+	board, err := svc.Leaderboard(context.Background(), model.LeaderboardQuery{Metric: model.MetricHonor, To: time.Now().UTC()})
+	if err != nil {
+		t.Fatalf("Leaderboard: %v", err)
+	}
+	if board.Entries == nil || len(board.Entries) != 0 {
+		t.Errorf("entries = %#v, want empty", board.Entries)
+	}
+}
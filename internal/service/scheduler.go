@@ -0,0 +1,149 @@
+package service
+
+import (
+	"SolverAPI/internal/repository"
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Значения планировщика по умолчанию
+const (
+	defaultSyncConcurrency = 4
+	syncUserTimeout        = time.Minute
+	syncListTimeout        = 30 * time.Second
+)
+
+// SchedulerConfig - параметры фоновой синхронизации
+type SchedulerConfig struct {
+	Interval    time.Duration // Период обхода реестра
+	Concurrency int           // Сколько пользователей синхронизируется одновременно
+	Jitter      time.Duration // Случайная задержка перед синхронизацией каждого пользователя
+}
+
+// SyncScheduler периодически синхронизирует всех пользователей из реестра
+// и записывает результат каждой синхронизации
+type SyncScheduler struct {
+	tracked repository.TrackedUserRepository
+	users   *UserService
+	cfg     SchedulerConfig
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // nil до Start и после Stop
+	wg     sync.WaitGroup
+}
+
+func NewSyncScheduler(tracked repository.TrackedUserRepository, users *UserService, cfg SchedulerConfig) *SyncScheduler {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = defaultSyncConcurrency
+	}
+	return &SyncScheduler{tracked: tracked, users: users, cfg: cfg}
+}
+
+// Start запускает обход реестра сразу и далее с периодом Interval.
+// При Interval <= 0 и при повторном вызове без Stop ничего не делает
+func (s *SyncScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil || s.cfg.Interval <= 0 {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.loop(ctx)
+}
+
+// Stop останавливает планировщик и ждет завершения текущего обхода.
+// После Stop планировщик можно снова запустить
+func (s *SyncScheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *SyncScheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce синхронизирует всех пользователей из реестра не более чем
+// по Concurrency одновременно и возвращается, когда обход закончен
+func (s *SyncScheduler) RunOnce(ctx context.Context) {
+	listCtx, cancel := context.WithTimeout(ctx, syncListTimeout)
+	users, err := s.tracked.ListTrackedUsers(listCtx)
+	cancel()
+	if err != nil {
+		slog.Warn("failed to list tracked users", "error", err)
+		return
+	}
+
+	sem := make(chan struct{}, s.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, user := range users {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(username string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.syncOne(ctx, username)
+		}(user.Username)
+	}
+	wg.Wait()
+}
+
+func (s *SyncScheduler) syncOne(ctx context.Context, username string) {
+	// Разносим запросы во времени, чтобы не упираться в лимит Codewars пачкой
+	if s.cfg.Jitter > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(rand.Int63n(int64(s.cfg.Jitter)))):
+		}
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, syncUserTimeout)
+	defer cancel()
+
+	_, err := s.users.RefreshUser(syncCtx, username)
+	if ctx.Err() != nil {
+		// Остановка планировщика - не ошибка синхронизации
+		return
+	}
+	if err != nil {
+		slog.Warn("scheduled user sync failed", "username", username, "error", err)
+	}
+
+	// Результат записываем и при отмене syncCtx по таймауту
+	recordCtx, cancelRecord := context.WithTimeout(context.WithoutCancel(ctx), syncListTimeout)
+	defer cancelRecord()
+	if recErr := s.tracked.RecordSyncResult(recordCtx, username, time.Now().UTC(), err); recErr != nil {
+		slog.Warn("failed to record sync result", "username", username, "error", recErr)
+	}
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSyncSchedulerRestartsAfterStop(t *testing.T) {
+	tracked := &fakeTrackedRepo{listed: make(chan struct{}, 1)}
+	scheduler := NewSyncScheduler(tracked, nil, SchedulerConfig{Interval: time.Hour})
+
+	// Каждый Start сразу запускает обход реестра
+	for run := range 2 {
+		scheduler.Start(context.Background())
+		select {
+		case <-tracked.listed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("run %d: scheduler did not start", run)
+		}
+		scheduler.Stop()
+	}
+}
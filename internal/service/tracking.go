@@ -0,0 +1,57 @@
+package service
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/internal/repository"
+	"context"
+	"fmt"
+	"time"
+)
+
+// TrackingService управляет реестром пользователей для фоновой синхронизации
+type TrackingService struct {
+	tracked repository.TrackedUserRepository
+	users   *UserService
+}
+
+func NewTrackingService(tracked repository.TrackedUserRepository, users *UserService) *TrackingService {
+	return &TrackingService{tracked: tracked, users: users}
+}
+
+// Track синхронизирует пользователя и добавляет его в реестр.
+// Несуществующий на Codewars пользователь не добавляется.
+// created == false, если пользователь уже отслеживался
+func (s *TrackingService) Track(ctx context.Context, username string) (user *model.TrackedUser, created bool, err error) {
+	synced, err := s.users.SyncUser(ctx, username)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created, err = s.tracked.TrackUser(ctx, username, model.TrackSourceManual)
+	if err != nil {
+		return nil, false, err
+	}
+	if created && !synced.Stale {
+		// Первая синхронизация уже выполнена, отмечаем ее в реестре
+		if err := s.tracked.RecordSyncResult(ctx, username, time.Now().UTC(), nil); err != nil {
+			return nil, false, err
+		}
+	}
+
+	user, err = s.tracked.GetTrackedUser(ctx, username)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get tracked user: %w", err)
+	}
+	return user, created, nil
+}
+
+// Untrack убирает пользователя из реестра; автоотслеживание клана его больше
+// не добавит, только явный Track. Сохраненные данные пользователя остаются
+func (s *TrackingService) Untrack(ctx context.Context, username string) error {
+	return s.tracked.UntrackUser(ctx, username)
+}
+
+// List возвращает реестр с результатами последних синхронизаций
+func (s *TrackingService) List(ctx context.Context) ([]model.TrackedUser, error) {
+	return s.tracked.ListTrackedUsers(ctx)
+}
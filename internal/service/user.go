@@ -5,29 +5,100 @@ import (
 	"SolverAPI/internal/repository"
 	"SolverAPI/pkg/codewars"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 )
 
+// completedBatchSize - сколько решенных задач сохраняется в БД за один раз
+const completedBatchSize = 200
+
 type UserService struct {
-	repo repository.UserRepository
-	cw   *codewars.Client
+	repo       repository.UserRepository
+	challenges repository.CompletedChallengeRepository
+	authored   repository.AuthoredChallengeRepository
+	snapshots  repository.UserSnapshotRepository
+	cw         CodewarsUsers
+	freshness  time.Duration // Сколько сохраненный пользователь считается свежим, 0 - всегда синхронизировать
+
+	clans *clanTracker // nil - соклановцы не отслеживаются
+}
+
+func NewUserService(
+	repo repository.UserRepository,
+	challenges repository.CompletedChallengeRepository,
+	authored repository.AuthoredChallengeRepository,
+	snapshots repository.UserSnapshotRepository,
+	cw CodewarsUsers,
+) *UserService {
+	return &UserService{repo: repo, challenges: challenges, authored: authored, snapshots: snapshots, cw: cw}
 }
 
-func NewUserService(repo repository.UserRepository, cw *codewars.Client) *UserService {
-	return &UserService{repo: repo, cw: cw}
+// SetFreshness задает, сколько после синхронизации пользователь отдается из БД
+// без запроса к Codewars. 0 - каждый GetUser синхронизирует пользователя
+func (s *UserService) SetFreshness(ttl time.Duration) {
+	s.freshness = ttl
+}
+
+// GetUser возвращает пользователя из БД, если он синхронизирован не раньше
+// чем freshness назад, иначе синхронизирует его. refresh - синхронизировать всегда,
+// в обход и БД, и кэша ответов клиента Codewars
+func (s *UserService) GetUser(ctx context.Context, username string, refresh bool) (*model.User, error) {
+	if refresh {
+		ctx = codewars.WithRevalidate(ctx)
+	} else if s.freshness > 0 {
+		user, err := s.repo.GetUser(ctx, username)
+		if err == nil && time.Since(user.SyncedAt) < s.freshness {
+			return user, nil
+		}
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	return s.SyncUser(ctx, username)
 }
 
 func (s *UserService) SyncUser(ctx context.Context, username string) (*model.User, error) {
-	//Получаем данные из Codewars API
-	cwUser, err := s.cw.GetUser(username)
+	user, err := s.fetchUser(ctx, username)
 	if err != nil {
+		//Codewars недоступен - отдаем последнюю сохраненную версию
+		if user, ok := s.staleUser(ctx, username, err); ok {
+			return user, nil
+		}
 		return nil, err
 	}
 
-	//Преобразуем в нашу модель
+	return user, nil
+}
+
+// RefreshUser синхронизирует пользователя без подмены данными из БД:
+// любая ошибка Codewars возвращается вызывающему
+func (s *UserService) RefreshUser(ctx context.Context, username string) (*model.User, error) {
+	return s.fetchUser(ctx, username)
+}
+
+// fetchUser получает пользователя из Codewars, сохраняет в БД
+// и отмечает соклановцев для отслеживания
+func (s *UserService) fetchUser(ctx context.Context, username string) (*model.User, error) {
+	//Получаем данные из Codewars API
+	cwUser, err := s.cw.GetUser(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync user: %w", err)
+	}
+
+	//Преобразуем в нашу модель. SyncedAt - время ответа Codewars:
+	//ответ из кэша клиента не делает данные свежее
+	now := time.Now()
+	syncedAt := cwUser.FetchedAt
+	if syncedAt.IsZero() {
+		syncedAt = now
+	}
 	user := &model.User{
 		CodewarsUser: *cwUser,
-		CreatedAt:    time.Now(),
+		CreatedAt:    now,
+		SyncedAt:     syncedAt,
 	}
 
 	//Сохраняем в БД
@@ -35,5 +106,105 @@ func (s *UserService) SyncUser(ctx context.Context, username string) (*model.Use
 		return nil, err
 	}
 
+	s.appendSnapshot(ctx, user, syncedAt)
+	s.trackClan(ctx, user)
+
 	return user, nil
 }
+
+// appendSnapshot добавляет точку в историю, если хонор или ранги изменились.
+// Ошибка не прерывает синхронизацию: пользователь уже сохранен, а снимок
+// сравнивается с последним снимком, а не с пользователем, поэтому
+// пропущенное изменение попадет в историю при следующей синхронизации
+func (s *UserService) appendSnapshot(ctx context.Context, user *model.User, at time.Time) {
+	snapshot := model.UserSnapshot{
+		Username:       user.Username,
+		Honor:          user.Honor,
+		OverallRank:    user.Ranks.Overall,
+		LanguageRanks:  user.Ranks.Languages,
+		TotalCompleted: user.CodeChallenges.TotalCompleted,
+		TakenAt:        at.UTC(),
+	}
+	if _, err := s.snapshots.AppendUserSnapshot(ctx, snapshot); err != nil {
+		slog.Warn("failed to save user snapshot", "username", user.Username, "error", err)
+	}
+}
+
+// staleUser возвращает пользователя из БД, если ошибка Codewars временная
+// и пользователь уже синхронизировался раньше
+func (s *UserService) staleUser(ctx context.Context, username string, cwErr error) (*model.User, bool) {
+	if !isUpstreamFailure(cwErr) {
+		return nil, false
+	}
+
+	ctx, cancel := staleContext(ctx)
+	defer cancel()
+
+	user, err := s.repo.GetUser(ctx, username)
+	if err != nil {
+		return nil, false
+	}
+	user.Stale = true
+
+	return user, true
+}
+
+// ListUsers возвращает страницу сохраненных пользователей и курсор следующей
+// страницы (nil, если страница последняя)
+func (s *UserService) ListUsers(ctx context.Context, query model.UserQuery) ([]model.User, *model.UserCursor, error) {
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	limit := query.Limit
+	query.Limit++
+
+	users, err := s.repo.ListUsers(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	if len(users) <= limit {
+		return users, nil, nil
+	}
+	users = users[:limit]
+	return users, model.CursorAfter(query, users[limit-1]), nil
+}
+
+// SyncCompletedChallenges загружает все решенные пользователем задачи
+// из Codewars, сохраняет их в БД и возвращает сохраненный список
+func (s *UserService) SyncCompletedChallenges(ctx context.Context, username string) ([]model.CompletedChallenge, error) {
+	batch := make([]model.CompletedChallenge, 0, completedBatchSize)
+
+	for ch, err := range s.cw.CompletedChallenges(ctx, username) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync completed challenges: %w", err)
+		}
+
+		batch = append(batch, ch)
+		if len(batch) == completedBatchSize {
+			if err := s.challenges.SaveCompletedChallenges(ctx, username, batch); err != nil {
+				return nil, fmt.Errorf("failed to save completed challenges: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := s.challenges.SaveCompletedChallenges(ctx, username, batch); err != nil {
+		return nil, fmt.Errorf("failed to save completed challenges: %w", err)
+	}
+
+	return s.challenges.GetCompletedChallenges(ctx, username)
+}
+
+// SyncAuthoredChallenges загружает созданные пользователем задачи
+// и сохраняет их ранг, теги и языки в БД
+func (s *UserService) SyncAuthoredChallenges(ctx context.Context, username string) ([]model.AuthoredChallenge, error) {
+	challenges, err := s.cw.AuthoredChallenges(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync authored challenges: %w", err)
+	}
+
+	if err := s.authored.SaveAuthoredChallenges(ctx, username, challenges); err != nil {
+		return nil, fmt.Errorf("failed to save authored challenges: %w", err)
+	}
+
+	return challenges, nil
+}
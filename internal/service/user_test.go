@@ -0,0 +1,62 @@
+package service
+
+import (
+	"SolverAPI/pkg/codewars/codewarstest"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSyncUserAppendsSnapshot(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	snapshots := &fakeSnapshotRepo{}
+	svc := NewUserService(newFakeUserRepo(), nil, nil, snapshots, srv.NewClient())
+	ctx := context.Background()
+
+	steps := []struct {
+		name    string
+		update  func(f *codewarstest.Fixtures)
+		refresh bool
+		want    int
+	}{
+		{name: "first sync writes a snapshot", want: 1},
+		{name: "unchanged profile is skipped", refresh: true, want: 1},
+		{
+			name: "changed honor writes a snapshot",
+			update: func(f *codewarstest.Fixtures) {
+				u := f.Users["some_user"]
+				u.Honor += 10
+				f.Users["some_user"] = u
+			},
+			refresh: true,
+			want:    2,
+		},
+	}
+
+	for _, step := range steps {
+		if step.update != nil {
+			srv.Update(step.update)
+		}
+		user, err := svc.GetUser(ctx, "some_user", step.refresh)
+		if err != nil {
+			t.Fatalf("%s: GetUser: %v", step.name, err)
+		}
+
+		snapshots.mu.Lock()
+		got := append(snapshots.snapshots[:0:0], snapshots.snapshots...)
+		snapshots.mu.Unlock()
+
+		if len(got) != step.want {
+			t.Fatalf("%s: %d snapshots, want %d", step.name, len(got), step.want)
+		}
+		last := got[len(got)-1]
+		if last.Honor != user.Honor {
+			t.Errorf("%s: snapshot honor = %d, want %d", step.name, last.Honor, user.Honor)
+		}
+		if last.TakenAt.Location() != time.UTC {
+			t.Errorf("%s: snapshot time %v is not UTC", step.name, last.TakenAt)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE users
+    DROP COLUMN codewars_id,
+    DROP COLUMN name,
+    DROP COLUMN clan,
+    DROP COLUMN leaderboard_position,
+    DROP COLUMN skills,
+    DROP COLUMN overall_rank,
+    DROP COLUMN overall_rank_name,
+    DROP COLUMN overall_rank_color,
+    DROP COLUMN overall_rank_score,
+    DROP COLUMN language_ranks,
+    DROP COLUMN total_authored,
+    DROP COLUMN total_completed;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN codewars_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN clan VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN leaderboard_position INTEGER,
+    ADD COLUMN skills JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN overall_rank INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN overall_rank_name VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN overall_rank_color VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN overall_rank_score INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN language_ranks JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN total_authored INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN total_completed INTEGER NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS completed_challenges (
+    username VARCHAR(255) NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    completed_languages JSONB NOT NULL DEFAULT '[]'::jsonb,
+    PRIMARY KEY (username, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_completed_challenges_completed_at ON completed_challenges(username, completed_at DESC);
+
+COMMIT;
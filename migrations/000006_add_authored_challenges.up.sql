@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS authored_challenges (
+    username VARCHAR(255) NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    rank INTEGER,
+    rank_name VARCHAR(64) NOT NULL DEFAULT '',
+    tags JSONB NOT NULL DEFAULT '[]'::jsonb,
+    languages JSONB NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (username, id)
+);
+
+COMMIT;
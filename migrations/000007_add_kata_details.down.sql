@@ -0,0 +1,21 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_katas_rank_id;
+
+ALTER TABLE katas
+    DROP COLUMN category,
+    DROP COLUMN description,
+    DROP COLUMN rank_id,
+    DROP COLUMN rank_name,
+    DROP COLUMN rank_color,
+    DROP COLUMN created_by,
+    DROP COLUMN approved_by,
+    DROP COLUMN published_at,
+    DROP COLUMN approved_at,
+    DROP COLUMN total_attempts,
+    DROP COLUMN total_completed,
+    DROP COLUMN total_stars,
+    DROP COLUMN vote_score,
+    DROP COLUMN contributors_wanted;
+
+COMMIT;
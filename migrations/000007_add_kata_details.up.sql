@@ -0,0 +1,21 @@
+BEGIN;
+
+ALTER TABLE katas
+    ADD COLUMN category VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN rank_id INTEGER,
+    ADD COLUMN rank_name VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN rank_color VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN created_by JSONB,
+    ADD COLUMN approved_by JSONB,
+    ADD COLUMN published_at TIMESTAMP,
+    ADD COLUMN approved_at TIMESTAMP,
+    ADD COLUMN total_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN total_completed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN total_stars INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN vote_score INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN contributors_wanted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_katas_rank_id ON katas(rank_id);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS kata_buffer (
+    filter_key TEXT NOT NULL,
+    kata_id VARCHAR(255) NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    discovered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (filter_key, kata_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kata_buffer_last_seen_at ON kata_buffer(filter_key, last_seen_at);
+
+COMMIT;
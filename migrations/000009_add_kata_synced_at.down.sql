@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE katas
+    DROP COLUMN synced_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE katas
+    ADD COLUMN synced_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE katas SET synced_at = added_at;
+
+COMMIT;
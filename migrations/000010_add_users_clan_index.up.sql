@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_users_clan ON users(clan);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    honor INTEGER NOT NULL,
+    overall_rank INTEGER NOT NULL DEFAULT 0,
+    overall_rank_name VARCHAR(64) NOT NULL DEFAULT '',
+    overall_rank_color VARCHAR(64) NOT NULL DEFAULT '',
+    overall_rank_score INTEGER NOT NULL DEFAULT 0,
+    language_ranks JSONB NOT NULL DEFAULT '{}'::jsonb,
+    total_completed INTEGER NOT NULL DEFAULT 0,
+    taken_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_snapshots_taken_at ON user_snapshots(username, taken_at);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tracked_users (
+    username VARCHAR(255) PRIMARY KEY,
+    source VARCHAR(32) NOT NULL DEFAULT 'manual',
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_error_at TIMESTAMP
+);
+
+COMMIT;
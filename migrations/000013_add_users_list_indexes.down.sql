@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_language_ranks;
+DROP INDEX IF EXISTS idx_users_updated_at;
+DROP INDEX IF EXISTS idx_users_overall_rank;
+DROP INDEX IF EXISTS idx_users_honor;
+
+COMMIT;
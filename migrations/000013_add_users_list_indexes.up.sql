@@ -0,0 +1,8 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_users_honor ON users(honor, username);
+CREATE INDEX IF NOT EXISTS idx_users_overall_rank ON users(overall_rank, overall_rank_score, username);
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users(updated_at, username);
+CREATE INDEX IF NOT EXISTS idx_users_language_ranks ON users USING GIN (language_ranks);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE users
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET DEFAULT NOW();
+
+UPDATE users SET
+    created_at = (created_at AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'),
+    updated_at = (updated_at AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone');
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- created_at и updated_at раньше заполнялись через NOW() в часовом поясе сессии БД,
+-- теперь приложение пишет обе метки в UTC. Переводим старые строки и умолчания
+UPDATE users SET
+    created_at = (created_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+    updated_at = (updated_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC';
+
+ALTER TABLE users
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE katas
+    ALTER COLUMN added_at SET DEFAULT NOW(),
+    ALTER COLUMN synced_at SET DEFAULT NOW();
+
+UPDATE katas SET
+    added_at = (added_at AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone'),
+    synced_at = (synced_at AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone');
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- added_at и synced_at раньше заполнялись временем Go в локальной зоне сервиса
+-- и через NOW() в часовом поясе сессии БД, теперь приложение пишет их в UTC.
+-- Переводим старые строки и умолчания, как для users в 000014
+UPDATE katas SET
+    added_at = (added_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC',
+    synced_at = (synced_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC';
+
+ALTER TABLE katas
+    ALTER COLUMN added_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN synced_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+
+COMMIT;
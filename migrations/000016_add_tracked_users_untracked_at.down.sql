@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM tracked_users WHERE untracked_at IS NOT NULL;
+
+ALTER TABLE tracked_users
+    DROP COLUMN IF EXISTS untracked_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Отказ от отслеживания запоминается, а не удаляет строку: иначе
+-- автоотслеживание клана снова добавит пользователя при следующей синхронизации
+ALTER TABLE tracked_users
+    ADD COLUMN IF NOT EXISTS untracked_at TIMESTAMP;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE tracked_users
+    ALTER COLUMN added_at SET DEFAULT NOW();
+
+UPDATE tracked_users SET
+    added_at = (added_at AT TIME ZONE 'UTC') AT TIME ZONE current_setting('TimeZone');
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- added_at заполнялся через NOW() в часовом поясе сессии БД, а last_* пишутся
+-- приложением в UTC. Переводим старые строки и умолчание, как для users в 000014
+UPDATE tracked_users SET
+    added_at = (added_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'UTC';
+
+ALTER TABLE tracked_users
+    ALTER COLUMN added_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_overall_rank_asc;
+DROP INDEX IF EXISTS idx_users_overall_rank_desc;
+
+CREATE INDEX IF NOT EXISTS idx_users_overall_rank ON users(overall_rank, overall_rank_score, username);
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Сортировка по рангу ставит пользователей без ранга в конец выражением
+-- (overall_rank <> 0) по убыванию и (overall_rank = 0) по возрастанию.
+-- Индекс по самим колонкам такой ORDER BY не обслуживает, поэтому нужен
+-- индекс по выражению для каждого направления; по убыванию он читается с конца
+DROP INDEX IF EXISTS idx_users_overall_rank;
+
+CREATE INDEX IF NOT EXISTS idx_users_overall_rank_desc
+    ON users((overall_rank <> 0), overall_rank, overall_rank_score, username);
+CREATE INDEX IF NOT EXISTS idx_users_overall_rank_asc
+    ON users((overall_rank = 0), overall_rank, overall_rank_score, username);
+
+COMMIT;
@@ -0,0 +1,159 @@
+package codewars
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen - запросы к Codewars временно не выполняются после серии сбоев
+var ErrCircuitOpen = errors.New("codewars: circuit breaker is open")
+
+// CircuitOpenError возвращается без обращения к Codewars, пока breaker открыт
+type CircuitOpenError struct {
+	RetryAfter time.Duration // Сколько осталось до пробного запроса
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("codewars: circuit breaker is open, retry after %s", e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerConfig задает circuit breaker. FailureThreshold <= 0 отключает его
+type BreakerConfig struct {
+	FailureThreshold int           // Подряд идущих сбоев до открытия
+	CoolDown         time.Duration // Сколько breaker остается открытым до пробного запроса
+}
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerStats - состояние breaker для /health и метрик
+type BreakerStats struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	Rejected            int64        `json:"rejected"` // Вызовов, отклоненных без запроса
+}
+
+// breaker - circuit breaker с состояниями closed -> open -> half_open -> closed.
+// В half_open пропускается ровно один пробный запрос
+type breaker struct {
+	threshold int
+	coolDown  time.Duration
+	clock     Clock
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	rejected int64
+}
+
+func newBreaker(cfg BreakerConfig, clock Clock) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	return &breaker{
+		threshold: cfg.FailureThreshold,
+		coolDown:  cfg.CoolDown,
+		clock:     clock,
+		state:     BreakerClosed,
+	}
+}
+
+// allow решает, можно ли выполнить запрос
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		elapsed := b.clock.Now().Sub(b.openedAt)
+		if elapsed < b.coolDown {
+			b.rejected++
+			return &CircuitOpenError{RetryAfter: b.coolDown - elapsed}
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return &CircuitOpenError{}
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record учитывает результат запроса. Сбоем считается только недоступность
+// Codewars; 404, 429 и ошибки разбора говорят о том, что сервис отвечает.
+// Отмена запроса вызывающим и отсутствие записи в режиме replay
+// ничего не говорят о состоянии Codewars
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case errors.Is(err, ErrUnavailable):
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+			b.state = BreakerOpen
+			b.openedAt = b.clock.Now()
+		}
+	case err == nil || isUpstreamAnswer(err):
+		b.state = BreakerClosed
+		b.failures = 0
+	}
+	b.probing = false
+}
+
+// isUpstreamAnswer - ошибка получена из ответа Codewars, а не из-за отмены запроса
+func isUpstreamAnswer(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrDecode)
+}
+
+func (b *breaker) Stats() BreakerStats {
+	if b == nil {
+		return BreakerStats{State: BreakerClosed}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Rejected:            b.rejected,
+	}
+	if b.state == BreakerOpen && b.clock.Now().Sub(b.openedAt) >= b.coolDown {
+		stats.State = BreakerHalfOpen // Следующий запрос будет пробным
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
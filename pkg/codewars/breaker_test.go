@@ -0,0 +1,145 @@
+package codewars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	unavailable := fmt.Errorf("%w: connection refused", ErrUnavailable)
+	notFound := &StatusError{StatusCode: 404, URL: "https://www.codewars.com/api/v1/users/nobody"}
+
+	// call - один вызов: пауза перед ним, результат запроса и ожидаемое состояние после.
+	// rejected - breaker должен отклонить вызов без запроса; pending - запрос
+	// пропущен, но результат еще не записан
+	type call struct {
+		advance   time.Duration
+		result    error
+		rejected  bool
+		pending   bool
+		wantState BreakerState
+	}
+
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "opens after threshold consecutive failures",
+			calls: []call{
+				{result: unavailable, wantState: BreakerClosed},
+				{result: unavailable, wantState: BreakerOpen},
+				{rejected: true, wantState: BreakerOpen},
+			},
+		},
+		{
+			name: "success resets failure count",
+			calls: []call{
+				{result: unavailable, wantState: BreakerClosed},
+				{result: nil, wantState: BreakerClosed},
+				{result: unavailable, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "upstream answer counts as success",
+			calls: []call{
+				{result: unavailable, wantState: BreakerClosed},
+				{result: notFound, wantState: BreakerClosed},
+				{result: unavailable, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "cancellation and replay miss are neutral",
+			calls: []call{
+				{result: unavailable, wantState: BreakerClosed},
+				{result: context.Canceled, wantState: BreakerClosed},
+				{result: ErrFixtureNotFound, wantState: BreakerClosed},
+				{result: unavailable, wantState: BreakerOpen},
+			},
+		},
+		{
+			name: "probe after cool down closes on success",
+			calls: []call{
+				{result: unavailable},
+				{result: unavailable, wantState: BreakerOpen},
+				{advance: 10 * time.Second, rejected: true, wantState: BreakerOpen},
+				{advance: 30 * time.Second, result: nil, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			calls: []call{
+				{result: unavailable},
+				{result: unavailable, wantState: BreakerOpen},
+				{advance: time.Minute, result: unavailable, wantState: BreakerOpen},
+				{rejected: true, wantState: BreakerOpen},
+			},
+		},
+		{
+			name: "half open admits a single probe",
+			calls: []call{
+				{result: unavailable},
+				{result: unavailable, wantState: BreakerOpen},
+				{advance: time.Minute, pending: true, wantState: BreakerHalfOpen},
+				{rejected: true, wantState: BreakerHalfOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newManualClock()
+			b := newBreaker(BreakerConfig{FailureThreshold: 2, CoolDown: 30 * time.Second}, clock)
+
+			var rejected int64
+			for i, c := range tt.calls {
+				clock.Advance(c.advance)
+
+				err := b.allow()
+				if c.rejected {
+					rejected++
+					if !errors.Is(err, ErrCircuitOpen) {
+						t.Fatalf("call %d: allow = %v, want ErrCircuitOpen", i, err)
+					}
+				} else {
+					if err != nil {
+						t.Fatalf("call %d: allow = %v, want nil", i, err)
+					}
+					if !c.pending {
+						b.record(c.result)
+					}
+				}
+
+				if c.wantState != "" {
+					if state := b.Stats().State; state != c.wantState {
+						t.Fatalf("call %d: state = %s, want %s", i, state, c.wantState)
+					}
+				}
+			}
+
+			if got := b.Stats().Rejected; got != rejected {
+				t.Errorf("rejected = %d, want %d", got, rejected)
+			}
+		})
+	}
+}
+
+func TestBreakerRetryAfter(t *testing.T) {
+	clock := newManualClock()
+	b := newBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: 30 * time.Second}, clock)
+
+	b.allow()
+	b.record(ErrUnavailable)
+	clock.Advance(10 * time.Second)
+
+	var openErr *CircuitOpenError
+	if err := b.allow(); !errors.As(err, &openErr) {
+		t.Fatalf("allow = %v, want CircuitOpenError", err)
+	}
+	if openErr.RetryAfter != 20*time.Second {
+		t.Errorf("RetryAfter = %v, want 20s", openErr.RetryAfter)
+	}
+}
@@ -0,0 +1,436 @@
+package codewars
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBufferInterval = 1 * time.Hour
+	// Таймаут обновления, запущенного вне Start (нет контекста жизненного цикла)
+	bufferRefreshTimeout = 30 * time.Second
+	// Сколько фильтров может держать буфер; самые старые вытесняются
+	maxBufferFilters = 64
+	// ID, которые поиск не возвращал дольше этого срока, удаляются из хранилища
+	bufferRetention = 7 * 24 * time.Hour
+	// Таймаут загрузки буфера из хранилища при Start
+	bufferLoadTimeout = 10 * time.Second
+)
+
+// BufferStore - постоянное хранилище буфера, чтобы он переживал перезапуски.
+// Реализуется repository.KataBufferRepository
+type BufferStore interface {
+	LoadKataBuffer(ctx context.Context) ([]model.KataBufferEntry, error)
+	MergeKataBuffer(ctx context.Context, filter model.KataFilter, filterKey string, ids []string, seenAt, staleBefore time.Time) error
+}
+
+var (
+	// ErrBufferEmpty - в буфере нет ни одной задачи и заполнить его не удалось
+	ErrBufferEmpty = errors.New("codewars: kata buffer is empty")
+	// ErrBufferStopped - буфер остановлен через Stop и не обновляется до Start
+	ErrBufferStopped = errors.New("codewars: kata buffer is stopped")
+)
+
+// bufferSnapshot - неизменяемый снимок буфера. Читатели получают его
+// без блокировок, обновление подменяет снимок целиком
+type bufferSnapshot struct {
+	ids       []string
+	seen      map[string]time.Time // Когда поиск последний раз возвращал ID
+	updatedAt time.Time
+}
+
+// refreshCall - выполняющееся обновление буфера
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// bufferBucket - ID задач, найденных по одному фильтру
+type bufferBucket struct {
+	filter   model.KataFilter
+	snapshot atomic.Pointer[bufferSnapshot]
+	inflight *refreshCall // Под KataBuffer.mu
+	lastUsed atomic.Int64 // UnixNano последнего чтения, для вытеснения
+}
+
+// KataBuffer хранит ID задач для выбора случайной задачи, отдельно для
+// каждого фильтра поиска. Обновляется в фоне по таймеру (после Start)
+// и по запросу через Refresh; пока идет обновление, читатели получают
+// предыдущий снимок
+type KataBuffer struct {
+	client   *Client
+	interval time.Duration
+	prefill  bool
+	store    BufferStore // nil - буфер только в памяти
+
+	mu      sync.Mutex
+	buckets map[string]*bufferBucket
+	ctx     context.Context // Контекст жизненного цикла, nil вне Start..Stop
+	cancel  context.CancelFunc
+	stopped bool // После Stop новые обновления не запускаются до Start
+	wg      sync.WaitGroup
+}
+
+func newKataBuffer(c *Client, interval time.Duration, prefill bool) *KataBuffer {
+	if interval <= 0 {
+		interval = defaultBufferInterval
+	}
+	return &KataBuffer{
+		client:   c,
+		interval: interval,
+		prefill:  prefill,
+		buckets:  make(map[string]*bufferBucket),
+	}
+}
+
+// SetStore подключает постоянное хранилище. Вызывать до Start
+func (b *KataBuffer) SetStore(store BufferStore) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.store = store
+}
+
+// Start загружает буфер из хранилища и запускает периодическое обновление.
+// Повторный вызов ничего не делает; после Stop буфер можно запустить снова
+func (b *KataBuffer) Start(ctx context.Context) {
+	b.mu.Lock()
+	if b.cancel != nil {
+		b.mu.Unlock()
+		return
+	}
+	store := b.store
+	b.mu.Unlock()
+
+	if store != nil {
+		loadCtx, cancel := context.WithTimeout(ctx, bufferLoadTimeout)
+		b.load(loadCtx, store)
+		cancel()
+	}
+
+	b.mu.Lock()
+	if b.cancel != nil {
+		b.mu.Unlock()
+		return
+	}
+	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.stopped = false
+	loopCtx := b.ctx
+	b.wg.Add(1)
+	b.mu.Unlock()
+
+	go b.loop(loopCtx)
+}
+
+// Stop останавливает обновление и ждет завершения фоновых горутин.
+// Обновления, запрошенные после Stop, не запускаются
+func (b *KataBuffer) Stop() {
+	b.mu.Lock()
+	cancel := b.cancel
+	b.ctx, b.cancel = nil, nil
+	b.stopped = true
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	b.wg.Wait()
+}
+
+func (b *KataBuffer) loop(ctx context.Context) {
+	defer b.wg.Done()
+
+	if b.prefill {
+		// Загруженный из хранилища свежий буфер не перезаполняется
+		b.Refresh()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.client.clock.After(b.interval):
+			for _, bucket := range b.allBuckets() {
+				b.startRefresh(bucket)
+			}
+		}
+	}
+}
+
+// load восстанавливает снимки из хранилища. Ошибка не фатальна:
+// буфер просто заполнится поиском
+func (b *KataBuffer) load(ctx context.Context, store BufferStore) {
+	entries, err := store.LoadKataBuffer(ctx)
+	if err != nil {
+		b.client.logger.Error("failed to load kata buffer", "error", err)
+		return
+	}
+
+	// Хранилище удаляет устаревшие ID только при обновлении фильтра,
+	// поэтому срок хранения проверяется и здесь
+	staleBefore := b.client.clock.Now().Add(-bufferRetention)
+
+	snapshots := make(map[string]*bufferSnapshot)
+	filters := make(map[string]model.KataFilter)
+	for _, entry := range entries {
+		if entry.LastSeenAt.Before(staleBefore) {
+			continue
+		}
+		snap, ok := snapshots[entry.FilterKey]
+		if !ok {
+			snap = &bufferSnapshot{seen: make(map[string]time.Time)}
+			snapshots[entry.FilterKey] = snap
+			filters[entry.FilterKey] = entry.Filter
+		}
+		snap.ids = append(snap.ids, entry.KataID)
+		snap.seen[entry.KataID] = entry.LastSeenAt
+		if entry.LastSeenAt.After(snap.updatedAt) {
+			snap.updatedAt = entry.LastSeenAt
+		}
+	}
+
+	for key, snap := range snapshots {
+		b.bucket(filters[key]).snapshot.Store(snap)
+	}
+	b.client.logger.Info("kata buffer loaded", "filters", len(snapshots), "entries", len(entries))
+}
+
+// bucket возвращает корзину фильтра, создавая ее при необходимости
+func (b *KataBuffer) bucket(filter model.KataFilter) *bufferBucket {
+	key := FilterKey(filter)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		b.evictLocked()
+		bucket = &bufferBucket{filter: filter}
+		bucket.snapshot.Store(&bufferSnapshot{})
+		b.buckets[key] = bucket
+	}
+	bucket.lastUsed.Store(b.client.clock.Now().UnixNano())
+	return bucket
+}
+
+// evictLocked освобождает место под новый фильтр. Корзина без фильтра
+// и обновляемые корзины не вытесняются; если вытеснить нечего, буфер
+// временно держит больше maxBufferFilters фильтров
+func (b *KataBuffer) evictLocked() {
+	if len(b.buckets) < maxBufferFilters {
+		return
+	}
+
+	var oldestKey string
+	var oldest int64
+	found := false
+	for key, bucket := range b.buckets {
+		if key == "" || bucket.inflight != nil {
+			continue
+		}
+		if used := bucket.lastUsed.Load(); !found || used < oldest {
+			oldestKey, oldest, found = key, used, true
+		}
+	}
+	if !found {
+		return
+	}
+	delete(b.buckets, oldestKey)
+}
+
+func (b *KataBuffer) allBuckets() []*bufferBucket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]*bufferBucket, 0, len(b.buckets))
+	for _, bucket := range b.buckets {
+		result = append(result, bucket)
+	}
+	return result
+}
+
+// Refresh запускает обновление буфера без фильтра в фоне, если он пуст или устарел.
+// Никогда не блокирует вызывающего
+func (b *KataBuffer) Refresh() {
+	b.RefreshFilter(model.KataFilter{})
+}
+
+// RefreshFilter - то же, что Refresh, для буфера с фильтром
+func (b *KataBuffer) RefreshFilter(filter model.KataFilter) {
+	bucket := b.bucket(filter)
+	snap := bucket.snapshot.Load()
+	if len(snap.ids) > 0 && b.client.clock.Now().Sub(snap.updatedAt) < b.interval {
+		return
+	}
+	b.startRefresh(bucket)
+}
+
+// startRefresh запускает обновление корзины или возвращает уже идущее
+func (b *KataBuffer) startRefresh(bucket *bufferBucket) *refreshCall {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bucket.inflight != nil {
+		return bucket.inflight
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	// wg.Add под b.mu: Stop выставляет stopped под тем же мьютексом,
+	// поэтому после него новых горутин для wg.Wait не появится
+	if b.stopped {
+		call.err = ErrBufferStopped
+		close(call.done)
+		return call
+	}
+	bucket.inflight = call
+
+	ctx, cancel := b.ctx, context.CancelFunc(func() {})
+	if ctx == nil {
+		ctx, cancel = context.WithTimeout(context.Background(), bufferRefreshTimeout)
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer cancel()
+
+		call.err = b.refresh(ctx, bucket)
+
+		b.mu.Lock()
+		bucket.inflight = nil
+		b.mu.Unlock()
+		close(call.done)
+	}()
+
+	return call
+}
+
+// refresh загружает ID задач и подменяет снимок корзины
+func (b *KataBuffer) refresh(ctx context.Context, bucket *bufferBucket) error {
+	key := FilterKey(bucket.filter)
+
+	ids, err := b.client.scrapeKataList(ctx, bucket.filter)
+	if err != nil && key == "" {
+		b.client.logger.Warn("kata search scrape failed, falling back to API list", "error", err)
+		ids, err = b.client.listKataIDs(ctx)
+	}
+	if err != nil {
+		b.client.logger.Error("failed to refresh kata buffer", "filter", key, "error", err)
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrBufferEmpty
+	}
+
+	now := b.client.clock.Now().UTC()
+	b.mu.Lock()
+	store := b.store
+	b.mu.Unlock()
+	if store != nil {
+		if err := store.MergeKataBuffer(ctx, bucket.filter, key, ids, now, now.Add(-bufferRetention)); err != nil {
+			b.client.logger.Error("failed to persist kata buffer", "filter", key, "error", err)
+		}
+	}
+
+	// Новые ID добавляются к уже известным, как и в хранилище,
+	// и так же удаляются, если поиск не возвращал их дольше bufferRetention
+	merged := bucket.snapshot.Load().merge(ids, now, now.Add(-bufferRetention))
+	bucket.snapshot.Store(merged)
+	b.client.logger.Info("kata buffer refreshed", "filter", key, "found", len(ids), "count", len(merged.ids))
+	return nil
+}
+
+// merge возвращает новый снимок: найденные ID с отметкой now и прежние ID,
+// которые поиск возвращал не раньше staleBefore
+func (s *bufferSnapshot) merge(found []string, now, staleBefore time.Time) *bufferSnapshot {
+	merged := &bufferSnapshot{
+		ids:       make([]string, 0, len(s.ids)+len(found)),
+		seen:      make(map[string]time.Time, len(s.ids)+len(found)),
+		updatedAt: now,
+	}
+	for _, id := range s.ids {
+		if seenAt := s.seen[id]; !seenAt.Before(staleBefore) {
+			merged.ids = append(merged.ids, id)
+			merged.seen[id] = seenAt
+		}
+	}
+	for _, id := range found {
+		if _, ok := merged.seen[id]; !ok {
+			merged.ids = append(merged.ids, id)
+		}
+		merged.seen[id] = now
+	}
+	return merged
+}
+
+// RandomID возвращает случайный ID из буфера без фильтра
+func (b *KataBuffer) RandomID(ctx context.Context) (string, error) {
+	return b.RandomIDFor(ctx, model.KataFilter{})
+}
+
+// RandomIDFor возвращает случайный ID задачи, подходящей под фильтр.
+// Если буфер фильтра пуст, ждет его заполнения, но не дольше, чем позволяет контекст
+func (b *KataBuffer) RandomIDFor(ctx context.Context, filter model.KataFilter) (string, error) {
+	bucket := b.bucket(filter)
+	if id, ok := bucket.snapshot.Load().random(); ok {
+		return id, nil
+	}
+
+	call := b.startRefresh(bucket)
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-call.done:
+	}
+	if call.err != nil {
+		return "", fmt.Errorf("failed to fill buffer: %w", call.err)
+	}
+
+	if id, ok := bucket.snapshot.Load().random(); ok {
+		return id, nil
+	}
+	return "", ErrBufferEmpty
+}
+
+func (s *bufferSnapshot) random() (string, bool) {
+	if len(s.ids) == 0 {
+		return "", false
+	}
+	return s.ids[rand.Intn(len(s.ids))], true
+}
+
+// BufferStats - размер и возраст буфера одного фильтра
+type BufferStats struct {
+	Filter     model.KataFilter `json:"filter"`
+	Size       int              `json:"size"`
+	UpdatedAt  *time.Time       `json:"updated_at,omitempty"`
+	AgeSeconds int64            `json:"age_seconds,omitempty"`
+}
+
+// Stats возвращает состояние всех фильтров буфера
+func (b *KataBuffer) Stats() []BufferStats {
+	buckets := b.allBuckets()
+
+	stats := make([]BufferStats, 0, len(buckets))
+	for _, bucket := range buckets {
+		snap := bucket.snapshot.Load()
+		s := BufferStats{Filter: bucket.filter, Size: len(snap.ids)}
+		if !snap.updatedAt.IsZero() {
+			updatedAt := snap.updatedAt
+			s.UpdatedAt = &updatedAt
+			s.AgeSeconds = int64(b.client.clock.Now().Sub(updatedAt).Seconds())
+		}
+		stats = append(stats, s)
+	}
+	slices.SortFunc(stats, func(a, b BufferStats) int {
+		return strings.Compare(FilterKey(a.Filter), FilterKey(b.Filter))
+	})
+	return stats
+}
@@ -0,0 +1,116 @@
+package codewars_test
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/pkg/codewars"
+	"SolverAPI/pkg/codewars/codewarstest"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKataBufferStopAndRestart(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	client := srv.NewClient()
+	ctx := context.Background()
+
+	client.Start(ctx)
+	client.Stop()
+
+	if _, err := client.GetRandomKataID(ctx); !errors.Is(err, codewars.ErrBufferStopped) {
+		t.Fatalf("err after Stop = %v, want ErrBufferStopped", err)
+	}
+
+	client.Start(ctx)
+	defer client.Stop()
+
+	id, err := client.GetRandomKataID(ctx)
+	if err != nil {
+		t.Fatalf("GetRandomKataID after restart: %v", err)
+	}
+	if _, ok := codewarstest.DefaultFixtures().Katas[id]; !ok {
+		t.Errorf("id %q is not a fixture kata", id)
+	}
+}
+
+func TestKataBufferDropsIDsPastRetention(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	clock := newFakeClock()
+	client := srv.NewClient(codewars.WithClock(clock))
+	ctx := context.Background()
+
+	if _, err := client.GetRandomKataID(ctx); err != nil {
+		t.Fatalf("GetRandomKataID: %v", err)
+	}
+	initial := client.BufferStats()[0]
+
+	// Задача пропала из поиска: через срок хранения ее не должно быть и в памяти
+	ids := slices.Sorted(maps.Keys(codewarstest.DefaultFixtures().Katas))
+	srv.Update(func(f *codewarstest.Fixtures) { delete(f.Katas, ids[0]) })
+	clock.Advance(8 * 24 * time.Hour)
+	client.RefreshBuffer()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats := client.BufferStats()[0]
+		if stats.UpdatedAt.After(*initial.UpdatedAt) {
+			if stats.Size != initial.Size-1 {
+				t.Errorf("size = %d, want %d", stats.Size, initial.Size-1)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("buffer was not refreshed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recordingStore запоминает отметки времени, переданные в MergeKataBuffer
+type recordingStore struct {
+	merged chan [2]time.Time
+}
+
+func (s *recordingStore) LoadKataBuffer(ctx context.Context) ([]model.KataBufferEntry, error) {
+	return nil, nil
+}
+
+func (s *recordingStore) MergeKataBuffer(ctx context.Context, filter model.KataFilter, filterKey string, ids []string, seenAt, staleBefore time.Time) error {
+	s.merged <- [2]time.Time{seenAt, staleBefore}
+	return nil
+}
+
+func TestKataBufferPersistsUTC(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	// Часы сервиса не в UTC: колонки TIMESTAMP без зоны должны получить UTC
+	clock := newFakeClock()
+	clock.now = clock.now.In(time.FixedZone("MSK", 3*60*60))
+	store := &recordingStore{merged: make(chan [2]time.Time, 1)}
+
+	client := srv.NewClient(codewars.WithClock(clock))
+	client.SetBufferStore(store)
+	client.RefreshBuffer()
+
+	select {
+	case got := <-store.merged:
+		for _, ts := range got {
+			if ts.Location() != time.UTC {
+				t.Errorf("time %v is in %v, want UTC", ts, ts.Location())
+			}
+		}
+		if !got[0].Equal(clock.Now()) {
+			t.Errorf("seenAt = %v, want %v", got[0], clock.Now())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("buffer was not persisted")
+	}
+}
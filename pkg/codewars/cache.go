@@ -0,0 +1,250 @@
+package codewars
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Endpoint - логический эндпоинт Codewars, к которому относится запрос
+type Endpoint string
+
+const (
+	EndpointUser      Endpoint = "user"
+	EndpointKata      Endpoint = "kata"
+	EndpointKataList  Endpoint = "kata_list"
+	EndpointCompleted Endpoint = "completed"
+	EndpointAuthored  Endpoint = "authored"
+	EndpointSearch    Endpoint = "search"
+)
+
+// Valid сообщает, что e - один из известных эндпоинтов
+func (e Endpoint) Valid() bool {
+	switch e {
+	case EndpointUser, EndpointKata, EndpointKataList, EndpointCompleted, EndpointAuthored, EndpointSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+// CacheConfig задает кэш ответов Codewars. MaxEntries <= 0 отключает кэш,
+// эндпоинты без TTL не кэшируются
+type CacheConfig struct {
+	MaxEntries int
+	TTL        map[Endpoint]time.Duration
+}
+
+// CacheStats - счетчики кэша для метрик
+type CacheStats struct {
+	Entries     int   `json:"entries"`
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Revalidated int64 `json:"revalidated"` // Ответы 304 на условный запрос
+	Evictions   int64 `json:"evictions"`
+}
+
+type cacheEntry struct {
+	key          string
+	endpoint     Endpoint
+	body         []byte
+	etag         string
+	lastModified string
+	expires      time.Time
+	fetchedAt    time.Time // Когда Codewars отдал или подтвердил (304) тело
+}
+
+type revalidateCtxKey struct{}
+
+// WithRevalidate помечает запросы ctx как требующие ответа Codewars:
+// свежая запись кэша не отдается сразу, а перепроверяется условным запросом
+func WithRevalidate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revalidateCtxKey{}, true)
+}
+
+func mustRevalidate(ctx context.Context) bool {
+	revalidate, _ := ctx.Value(revalidateCtxKey{}).(bool)
+	return revalidate
+}
+
+// responseCache - LRU-кэш тел ответов с TTL по эндпоинтам.
+// Просроченные записи не удаляются сразу: их ETag/Last-Modified
+// используются для условного запроса
+type responseCache struct {
+	maxEntries int
+	ttl        map[Endpoint]time.Duration
+
+	mu    sync.Mutex
+	order *list.List // Начало списка - недавно использованные записи
+	items map[string]*list.Element
+
+	hits        atomic.Int64
+	misses      atomic.Int64
+	revalidated atomic.Int64
+	evictions   atomic.Int64
+}
+
+func newResponseCache(cfg CacheConfig) *responseCache {
+	if cfg.MaxEntries <= 0 {
+		return nil
+	}
+	return &responseCache{
+		maxEntries: cfg.MaxEntries,
+		ttl:        cfg.TTL,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// lookup возвращает запись и признак ее свежести
+func (rc *responseCache) lookup(endpoint Endpoint, key string, now time.Time) (*cacheEntry, bool) {
+	if rc == nil || rc.ttl[endpoint] <= 0 {
+		return nil, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	el, ok := rc.items[key]
+	if !ok {
+		rc.misses.Add(1)
+		return nil, false
+	}
+	rc.order.MoveToFront(el)
+
+	entry := el.Value.(*cacheEntry)
+	if now.Before(entry.expires) {
+		rc.hits.Add(1)
+		return entry, true
+	}
+	return entry, false
+}
+
+// conditionalHeaders добавляет к запросу валидаторы просроченной записи
+func (e *cacheEntry) conditionalHeaders(header http.Header) http.Header {
+	if e == nil || (e.etag == "" && e.lastModified == "") {
+		return header
+	}
+
+	h := header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	if e.etag != "" {
+		h.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		h.Set("If-Modified-Since", e.lastModified)
+	}
+	return h
+}
+
+// store сохраняет ответ 200
+func (rc *responseCache) store(endpoint Endpoint, key string, resp *response, now time.Time) {
+	ttl := rc.endpointTTL(endpoint)
+	if ttl <= 0 {
+		return
+	}
+
+	entry := &cacheEntry{
+		key:          key,
+		endpoint:     endpoint,
+		body:         resp.body,
+		etag:         resp.header.Get("ETag"),
+		lastModified: resp.header.Get("Last-Modified"),
+		expires:      now.Add(ttl),
+		fetchedAt:    now,
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Промах засчитан в lookup только для отсутствующих записей,
+	// обновление просроченной записи без 304 - тоже промах
+	if el, ok := rc.items[key]; ok {
+		rc.misses.Add(1)
+		el.Value = entry
+		rc.order.MoveToFront(el)
+		return
+	}
+
+	rc.items[key] = rc.order.PushFront(entry)
+	for rc.order.Len() > rc.maxEntries {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.items, oldest.Value.(*cacheEntry).key)
+		rc.evictions.Add(1)
+	}
+}
+
+// revalidate продлевает запись после ответа 304
+func (rc *responseCache) revalidate(entry *cacheEntry, now time.Time) {
+	rc.revalidated.Add(1)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, ok := rc.items[entry.key]; ok && el.Value == entry {
+		updated := *entry
+		updated.expires = now.Add(rc.ttl[entry.endpoint])
+		updated.fetchedAt = now
+		el.Value = &updated
+	}
+}
+
+func (rc *responseCache) endpointTTL(endpoint Endpoint) time.Duration {
+	if rc == nil {
+		return 0
+	}
+	return rc.ttl[endpoint]
+}
+
+// purge удаляет записи эндпоинта (пустой endpoint - все записи)
+func (rc *responseCache) purge(endpoint Endpoint) int {
+	if rc == nil {
+		return 0
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	purged := 0
+	for el := rc.order.Front(); el != nil; {
+		next := el.Next()
+		entry := el.Value.(*cacheEntry)
+		if endpoint == "" || entry.endpoint == endpoint {
+			rc.order.Remove(el)
+			delete(rc.items, entry.key)
+			purged++
+		}
+		el = next
+	}
+	return purged
+}
+
+func (rc *responseCache) Stats() CacheStats {
+	if rc == nil {
+		return CacheStats{}
+	}
+
+	rc.mu.Lock()
+	entries := rc.order.Len()
+	rc.mu.Unlock()
+
+	return CacheStats{
+		Entries:     entries,
+		Hits:        rc.hits.Load(),
+		Misses:      rc.misses.Load(),
+		Revalidated: rc.revalidated.Load(),
+		Evictions:   rc.evictions.Load(),
+	}
+}
+
+// PurgeCache очищает кэш ответов эндпоинта или весь кэш, если endpoint пустой.
+// Возвращает число удаленных записей
+func (c *Client) PurgeCache(endpoint Endpoint) int {
+	return c.cache.purge(endpoint)
+}
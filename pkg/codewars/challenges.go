@@ -0,0 +1,70 @@
+package codewars
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+)
+
+// completedPage - одна страница ответа /users/{user}/code-challenges/completed
+type completedPage struct {
+	TotalPages int                        `json:"totalPages"`
+	TotalItems int                        `json:"totalItems"`
+	Data       []model.CompletedChallenge `json:"data"`
+}
+
+func (c *Client) getCompletedPage(ctx context.Context, username string, page int) (*completedPage, error) {
+	u := fmt.Sprintf("%s/users/%s/code-challenges/completed?page=%d", c.baseURL, url.PathEscape(username), page)
+
+	var data completedPage
+	if _, err := c.getJSON(ctx, EndpointCompleted, u, &data); err != nil {
+		return nil, fmt.Errorf("failed to get completed challenges page %d: %w", page, err)
+	}
+	return &data, nil
+}
+
+// CompletedChallenges постранично обходит решенные пользователем задачи.
+// Следующая страница запрашивается только когда предыдущая прочитана;
+// при отмене контекста итерация прекращается с ошибкой ctx.Err().
+func (c *Client) CompletedChallenges(ctx context.Context, username string) iter.Seq2[model.CompletedChallenge, error] {
+	return func(yield func(model.CompletedChallenge, error) bool) {
+		for page := 0; ; page++ {
+			if err := ctx.Err(); err != nil {
+				yield(model.CompletedChallenge{}, err)
+				return
+			}
+
+			data, err := c.getCompletedPage(ctx, username, page)
+			if err != nil {
+				yield(model.CompletedChallenge{}, err)
+				return
+			}
+
+			for _, ch := range data.Data {
+				if !yield(ch, nil) {
+					return
+				}
+			}
+
+			if page+1 >= data.TotalPages || len(data.Data) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// AuthoredChallenges возвращает задачи, созданные пользователем.
+// Codewars отдает этот список целиком, без пагинации.
+func (c *Client) AuthoredChallenges(ctx context.Context, username string) ([]model.AuthoredChallenge, error) {
+	u := fmt.Sprintf("%s/users/%s/code-challenges/authored", c.baseURL, url.PathEscape(username))
+
+	var data struct {
+		Data []model.AuthoredChallenge `json:"data"`
+	}
+	if _, err := c.getJSON(ctx, EndpointAuthored, u, &data); err != nil {
+		return nil, fmt.Errorf("failed to get authored challenges: %w", err)
+	}
+	return data.Data, nil
+}
@@ -7,160 +7,276 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math/rand"
+	"log/slog"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
-	"sync"
 	"time"
 )
 
+// maxBodyBytes ограничивает размер читаемого ответа
+const maxBodyBytes = 10 * 1024 * 1024 // 10MB
+
 type Client struct {
-	baseURL     string
-	httpClient  *http.Client
-	kataBuffer  []string   // Буфер ID задач
-	lastUpdated time.Time  // Время последнего обновления
-	bufferMutex sync.Mutex // Для потокобезопасности
+	baseURL    string
+	searchURL  string
+	userAgent  string
+	httpClient *http.Client
+	logger     *slog.Logger
+	clock      Clock
+	retry      RetryPolicy
+	limiter    *rateLimiter   // nil - без ограничения
+	cache      *responseCache // nil - кэш отключен
+	flights    *coalescer
+	breaker    *breaker // nil - breaker отключен
+	buffer     *KataBuffer
+	metrics    *transportMetrics
+	drift      *driftDetector // nil - вне режима записи
+
+	// Параметры из опций, из которых собираются поля выше
+	baseHTTPClient *http.Client
+	transport      http.RoundTripper
+	timeout        time.Duration
+	rateLimit      RateLimit
+	cacheConfig    CacheConfig
+	breakerConfig  BreakerConfig
+	bufferInterval time.Duration
+	prefill        bool
+	mode           Mode
+	fixturesDir    string
 }
 
-func NewClient(baseURL string) *Client {
+func NewClient(baseURL string, opts ...Option) *Client {
 	c := &Client{
-		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		searchURL: defaultSearchURL,
+		userAgent: defaultUserAgent,
+		logger:    slog.Default(),
+		clock:     systemClock{},
+		retry:     DefaultRetryPolicy(),
+		prefill:   true,
+		mode:      ModeLive,
+		flights:   newCoalescer(),
+		metrics:   newTransportMetrics(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.httpClient = c.buildHTTPClient()
+	c.limiter = newRateLimiter(c.rateLimit, c.clock)
+	c.cache = newResponseCache(c.cacheConfig)
+	c.breaker = newBreaker(c.breakerConfig, c.clock)
+	c.buffer = newKataBuffer(c, c.bufferInterval, c.prefill)
+	if c.mode == ModeRecord {
+		c.drift = newDriftDetector()
 	}
 
-	// Первоначальное заполнение буфера
-	go c.RefreshBuffer()
 	return c
 }
 
-// Автоматическое обновление буфера
-func (c *Client) RefreshBuffer() {
-	c.bufferMutex.Lock()
-	defer c.bufferMutex.Unlock()
+// Start запускает фоновые задачи клиента (обновление буфера задач)
+func (c *Client) Start(ctx context.Context) {
+	c.buffer.Start(ctx)
+}
 
-	if time.Since(c.lastUpdated) < 1*time.Hour && len(c.kataBuffer) > 0 {
-		return // Не обновляем чаще чем раз в час
-	}
+// Stop останавливает фоновые задачи клиента
+func (c *Client) Stop() {
+	c.buffer.Stop()
+}
 
-	ids, err := c.scrapeKataList()
-	if err != nil {
-		log.Printf("Failed to refresh kata buffer: %v", err)
-		return
-	}
+// Buffer возвращает буфер ID задач
+func (c *Client) Buffer() *KataBuffer {
+	return c.buffer
+}
 
-	c.kataBuffer = ids
-	c.lastUpdated = time.Now()
-	log.Printf("Kata buffer refreshed, %d tasks available", len(ids))
+// SetBufferStore подключает постоянное хранилище буфера задач. Вызывать до Start
+func (c *Client) SetBufferStore(store BufferStore) {
+	c.buffer.SetStore(store)
 }
 
-func (c *Client) GetUser(username string) (*model.CodewarsUser, error) {
-	url := fmt.Sprintf("%s/users/%s", c.baseURL, username)
-	log.Printf("Requesting user from URL: %s", url) // Добавьте это
+// BufferStats возвращает размер и возраст буфера по фильтрам
+func (c *Client) BufferStats() []BufferStats {
+	return c.buffer.Stats()
+}
 
-	//Создаем GET-запрос с контекстом
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// RefreshBuffer обновляет буфер в фоне, если он пуст или устарел
+func (c *Client) RefreshBuffer() {
+	c.buffer.Refresh()
+}
+
+// response - успешный ответ Codewars (200 или 304 на условный запрос)
+type response struct {
+	body        []byte
+	header      http.Header
+	notModified bool
+}
+
+// fetched - тело ответа со статусом 200 и время, когда его отдал Codewars.
+// Для ответа из кэша это время исходного запроса или последней перепроверки
+type fetched struct {
+	body []byte
+	at   time.Time
+}
+
+// get выполняет GET-запрос и возвращает тело ответа со статусом 200.
+// Свежий ответ отдается из кэша, просроченный - перепроверяется условным запросом.
+// Для ctx из WithRevalidate перепроверяется и свежий ответ
+func (c *Client) get(ctx context.Context, endpoint Endpoint, rawURL string, header http.Header) (*fetched, error) {
+	cached, fresh := c.cache.lookup(endpoint, rawURL, c.clock.Now())
+	if fresh && !mustRevalidate(ctx) {
+		return &fetched{body: cached.body, at: cached.fetchedAt}, nil
 	}
 
-	//Выполняем запрос через наш httpClient (с таймаутом)
-	resp, err := c.httpClient.Do(req)
+	ctx = withEndpoint(ctx, endpoint)
+
+	// Одновременные запросы одного ресурса выполняются один раз
+	key := rawURL + "|" + header.Get("Accept")
+	return c.flights.do(ctx, key, func(ctx context.Context) (*fetched, error) {
+		return c.fetch(ctx, endpoint, rawURL, header, cached)
+	})
+}
+
+// fetch запрашивает ресурс с повторами и обновляет кэш.
+// cached - запись кэша для условного запроса или nil
+func (c *Client) fetch(ctx context.Context, endpoint Endpoint, rawURL string, header http.Header, cached *cacheEntry) (*fetched, error) {
+	header = cached.conditionalHeaders(header)
+
+	resp, err := c.withRetry(ctx, rawURL, func() (*response, error) {
+		return c.doGet(ctx, rawURL, header)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close() // Важно закрывать тело ответа
 
-	//Проверяем статус код
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	now := c.clock.Now()
+	if resp.notModified && cached != nil {
+		c.cache.revalidate(cached, now)
+		return &fetched{body: cached.body, at: now}, nil
 	}
+	c.cache.store(endpoint, rawURL, resp, now)
 
-	//Читаем и парсим JSON
-	var user model.CodewarsUser
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+	return &fetched{body: resp.body, at: now}, nil
+}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("user %q not found on Codewars", username)
+// doGet выполняет одну попытку запроса.
+// Ошибки статуса и сети приводятся к типам из errors.go
+func (c *Client) doGet(ctx context.Context, rawURL string, header http.Header) (*response, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
 	}
 
-	// 5. Возвращаем результат
-	return &user, nil
+	resp, err := c.roundTrip(ctx, rawURL, header)
+	c.breaker.record(err)
+	return resp, err
 }
 
-// GetKata возвращает информацию о задаче по ID
-func (c *Client) GetKata(ctx context.Context, id string) (*model.CodewarsKata, error) {
-	url := fmt.Sprintf("%s/code-challenges/%s", c.baseURL, id)
+func (c *Client) roundTrip(ctx context.Context, rawURL string, header http.Header) (*response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("User-Agent", c.userAgent)
+	for k, v := range header {
+		req.Header[k] = v
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		// Нет записи в режиме replay - не сбой Codewars: без повторов,
+		// без учета в breaker и без подмены сохраненными данными
+		if errors.Is(err, ErrFixtureNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusNotModified && isConditional(req) {
+		return &response{header: resp.Header, notModified: true}, nil
 	}
 
-	var kata model.CodewarsKata
-	if err := json.NewDecoder(resp.Body).Decode(&kata); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := checkStatus(resp, c.clock.Now()); err != nil {
+		return nil, err
 	}
 
-	return &kata, nil
-}
-
-// Метод для получения конкретной задачи
-func (c *Client) GetKataByID(ctx context.Context, id string) (*model.CodewarsKata, error) {
-	url := fmt.Sprintf("%s/code-challenges/%s", c.baseURL, id)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response: %w", ErrUnavailable, err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return &response{body: body, header: resp.Header}, nil
+}
+
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// getJSON выполняет GET-запрос и декодирует JSON-ответ в v.
+// Возвращает время, когда ответ получен от Codewars
+func (c *Client) getJSON(ctx context.Context, endpoint Endpoint, rawURL string, v any) (time.Time, error) {
+	res, err := c.get(ctx, endpoint, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return time.Time{}, err
 	}
-	defer resp.Body.Close()
+	body := res.body
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if err := json.Unmarshal(body, v); err != nil {
+		return time.Time{}, &DecodeError{URL: rawURL, Err: err}
 	}
 
-	var kata model.CodewarsKata
-	if err := json.NewDecoder(resp.Body).Decode(&kata); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// При записи сверяем ответ с моделью, чтобы заметить изменения API
+	if c.drift != nil {
+		if fields := c.drift.check(endpoint, body, v); len(fields) > 0 {
+			c.logger.Warn("codewars response has fields missing from model",
+				"endpoint", endpoint, "url", rawURL, "fields", fields)
+		}
 	}
 
-	return &kata, nil
+	return res.at, nil
 }
 
-// метод для заполнения буфера
-func (c *Client) fillKataBuffer(ctx context.Context) error {
-	c.bufferMutex.Lock()
-	defer c.bufferMutex.Unlock()
+func (c *Client) GetUser(ctx context.Context, username string) (*model.CodewarsUser, error) {
+	u := fmt.Sprintf("%s/users/%s", c.baseURL, url.PathEscape(username))
+	c.logger.Debug("requesting codewars user", "url", u)
 
-	url := fmt.Sprintf("%s/code-challenges?page=0&pageSize=50", c.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	var user model.CodewarsUser
+	fetchedAt, err := c.getJSON(ctx, EndpointUser, u, &user)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get user %q: %w", username, err)
 	}
+	user.FetchedAt = fetchedAt
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+	return &user, nil
+}
+
+// GetKata возвращает информацию о задаче по ID
+func (c *Client) GetKata(ctx context.Context, id string) (*model.CodewarsKata, error) {
+	return c.GetKataByID(ctx, id)
+}
+
+// Метод для получения конкретной задачи
+func (c *Client) GetKataByID(ctx context.Context, id string) (*model.CodewarsKata, error) {
+	u := fmt.Sprintf("%s/code-challenges/%s", c.baseURL, url.PathEscape(id))
+
+	var kata model.CodewarsKata
+	if _, err := c.getJSON(ctx, EndpointKata, u, &kata); err != nil {
+		return nil, fmt.Errorf("failed to get kata %q: %w", id, err)
 	}
-	defer resp.Body.Close()
+
+	return &kata, nil
+}
+
+// listKataIDs получает ID задач из списка API (запасной источник для буфера)
+func (c *Client) listKataIDs(ctx context.Context) ([]string, error) {
+	u := fmt.Sprintf("%s/code-challenges?page=0&pageSize=50", c.baseURL)
 
 	var data struct {
 		Data []struct {
@@ -168,16 +284,16 @@ func (c *Client) fillKataBuffer(ctx context.Context) error {
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return err
+	if _, err := c.getJSON(ctx, EndpointKataList, u, &data); err != nil {
+		return nil, err
 	}
 
-	c.kataBuffer = make([]string, 0, len(data.Data))
+	ids := make([]string, 0, len(data.Data))
 	for _, kata := range data.Data {
-		c.kataBuffer = append(c.kataBuffer, kata.ID)
+		ids = append(ids, kata.ID)
 	}
 
-	return nil
+	return ids, nil
 }
 
 // Новый метод для получения случайной задачи
@@ -189,75 +305,12 @@ func (c *Client) GetRandomKataFromBuffer(ctx context.Context) (*model.CodewarsKa
 	return c.GetKataByID(ctx, id)
 }
 
-func (c *Client) scrapeKataList() ([]string, error) {
-	//Создаем HTTP-запрос с таймаутом
-	req, err := http.NewRequest("GET", "https://www.codewars.com/kata/search", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Устанавливаем заголовки, чтобы имитировать браузер
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "text/html")
-
-	// Выполняем запрос с таймаутом
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("scrape request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	//Проверяем статус код
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
-	}
-
-	// Читаем тело ответа с ограничением по размеру
-	maxBytes := 10 * 1024 * 1024 // 10MB
-	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Регулярка для поиска ID задач в HTML
-	re := regexp.MustCompile(`/kata/([a-f0-9]{24})`)
-	matches := re.FindAllStringSubmatch(string(body), -1)
-
-	if len(matches) == 0 {
-		return nil, errors.New("no kata IDs found in HTML")
-	}
-
-	// Убираем дубликаты через map
-	uniqueIDs := make(map[string]struct{})
-	for _, match := range matches {
-		if len(match) > 1 {
-			uniqueIDs[match[1]] = struct{}{}
-		}
-	}
-
-	//Конвертируем в слайс
-	result := make([]string, 0, len(uniqueIDs))
-	for id := range uniqueIDs {
-		result = append(result, id)
-	}
-
-	// Проверяем, что нашли хотя бы несколько задач
-	if len(result) < 5 {
-		return nil, fmt.Errorf("found too few katas (%d), possible parsing error", len(result))
-	}
-
-	return result, nil
-}
-
+// GetRandomKataID возвращает случайный ID задачи из буфера
 func (c *Client) GetRandomKataID(ctx context.Context) (string, error) {
-	c.bufferMutex.Lock()
-	defer c.bufferMutex.Unlock()
-
-	if len(c.kataBuffer) == 0 {
-		if err := c.fillKataBuffer(ctx); err != nil {
-			return "", fmt.Errorf("failed to fill buffer: %w", err)
-		}
-	}
+	return c.buffer.RandomID(ctx)
+}
 
-	return c.kataBuffer[rand.Intn(len(c.kataBuffer))], nil
+// GetRandomKataIDMatching возвращает случайный ID задачи, подходящей под фильтр
+func (c *Client) GetRandomKataIDMatching(ctx context.Context, filter model.KataFilter) (string, error) {
+	return c.buffer.RandomIDFor(ctx, filter)
 }
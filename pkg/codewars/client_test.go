@@ -0,0 +1,375 @@
+package codewars_test
+
+import (
+	"SolverAPI/pkg/codewars"
+	"SolverAPI/pkg/codewars/codewarstest"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+const userPath = "/api/v1/users/some_user"
+
+// fakeClock не ждет: After сразу срабатывает и сдвигает текущее время
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+func retryPolicy() codewars.RetryPolicy {
+	return codewars.RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+}
+
+func TestGetUserRetriesServerError(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	fault := codewarstest.ServerError
+	fault.Times = 2
+	srv.Inject(userPath, fault)
+
+	clock := newFakeClock()
+	client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()), codewars.WithClock(clock))
+
+	user, err := client.GetUser(context.Background(), "some_user")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.Username != "some_user" {
+		t.Errorf("username = %q, want some_user", user.Username)
+	}
+	if hits := srv.Hits(userPath); hits != 3 {
+		t.Errorf("hits = %d, want 3", hits)
+	}
+	if waits := clock.Waits(); len(waits) != 2 {
+		t.Errorf("waits = %v, want 2 pauses", waits)
+	}
+}
+
+func TestGetUserServerErrorExhaustsRetries(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+	srv.Inject(userPath, codewarstest.ServerError)
+
+	client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()), codewars.WithClock(newFakeClock()))
+
+	_, err := client.GetUser(context.Background(), "some_user")
+	if !errors.Is(err, codewars.ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+	var statusErr *codewars.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 500 {
+		t.Errorf("err = %v, want StatusError 500", err)
+	}
+	if hits := srv.Hits(userPath); hits != 3 {
+		t.Errorf("hits = %d, want 3", hits)
+	}
+}
+
+func TestGetUserHonorsRetryAfter(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	fault := codewarstest.RateLimited(2 * time.Second)
+	fault.Times = 1
+	srv.Inject(userPath, fault)
+
+	clock := newFakeClock()
+	client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()), codewars.WithClock(clock))
+
+	if _, err := client.GetUser(context.Background(), "some_user"); err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	waits := clock.Waits()
+	if len(waits) != 1 || waits[0] != 2*time.Second {
+		t.Errorf("waits = %v, want [2s] from Retry-After", waits)
+	}
+}
+
+func TestGetUserRetryAfterBeyondMaxDelay(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+	srv.Inject(userPath, codewarstest.RateLimited(time.Minute))
+
+	client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()), codewars.WithClock(newFakeClock()))
+
+	_, err := client.GetUser(context.Background(), "some_user")
+	var rateLimit *codewars.RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("err = %v, want RateLimitError", err)
+	}
+	if rateLimit.RetryAfter != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", rateLimit.RetryAfter)
+	}
+	if hits := srv.Hits(userPath); hits != 1 {
+		t.Errorf("hits = %d, want 1: retry must not wait longer than MaxDelay", hits)
+	}
+}
+
+func TestGetUserRevalidatesExpiredCache(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	clock := newFakeClock()
+	client := srv.NewClient(
+		codewars.WithClock(clock),
+		codewars.WithCache(codewars.CacheConfig{
+			MaxEntries: 10,
+			TTL:        map[codewars.Endpoint]time.Duration{codewars.EndpointUser: time.Minute},
+		}),
+	)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := client.GetUser(ctx, "some_user"); err != nil {
+			t.Fatalf("GetUser: %v", err)
+		}
+	}
+	if hits := srv.Hits(userPath); hits != 1 {
+		t.Fatalf("hits = %d, want 1: fresh entry must be served from cache", hits)
+	}
+
+	clock.Advance(2 * time.Minute)
+	user, err := client.GetUser(ctx, "some_user")
+	if err != nil {
+		t.Fatalf("GetUser after expiry: %v", err)
+	}
+	if user.Username != "some_user" {
+		t.Errorf("username = %q, want some_user", user.Username)
+	}
+	if hits := srv.Hits(userPath); hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+
+	stats := client.Stats().Cache
+	if stats.Hits != 1 || stats.Revalidated != 1 {
+		t.Errorf("cache stats = %+v, want 1 hit and 1 revalidation", stats)
+	}
+}
+
+func TestGetUserFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault codewarstest.Fault
+		want  error
+	}{
+		{"not found", codewarstest.NotFound, codewars.ErrNotFound},
+		{"malformed", codewarstest.Malformed, codewars.ErrDecode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+			defer srv.Close()
+			srv.Inject(userPath, tt.fault)
+
+			client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()), codewars.WithClock(newFakeClock()))
+
+			_, err := client.GetUser(context.Background(), "some_user")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if errors.Is(err, codewars.ErrUnavailable) {
+				t.Errorf("err = %v must not be ErrUnavailable", err)
+			}
+			if hits := srv.Hits(userPath); hits != 1 {
+				t.Errorf("hits = %d, want 1: error is not retryable", hits)
+			}
+		})
+	}
+}
+
+func TestCompletedChallengesPages(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+	srv.SetPageSize(1)
+
+	client := srv.NewClient()
+
+	var ids []string
+	for challenge, err := range client.CompletedChallenges(context.Background(), "some_user") {
+		if err != nil {
+			t.Fatalf("CompletedChallenges: %v", err)
+		}
+		ids = append(ids, challenge.ID)
+	}
+
+	want := len(codewarstest.DefaultFixtures().Completed["some_user"])
+	if want < 2 || len(ids) != want {
+		t.Errorf("got %d challenges, want %d (more than one page)", len(ids), want)
+	}
+}
+
+func TestServerRejectsInvalidPage(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{"completed without page", srv.APIURL() + "/users/some_user/code-challenges/completed", http.StatusOK},
+		{"completed page past end", srv.APIURL() + "/users/some_user/code-challenges/completed?page=100", http.StatusOK},
+		{"completed negative page", srv.APIURL() + "/users/some_user/code-challenges/completed?page=-1", http.StatusBadRequest},
+		{"completed non-numeric page", srv.APIURL() + "/users/some_user/code-challenges/completed?page=x", http.StatusBadRequest},
+		{"search negative page", srv.SearchURL() + "?page=-1", http.StatusBadRequest},
+		{"search with language negative page", srv.SearchURL() + "/go?page=-2", http.StatusBadRequest},
+		{"search first page", srv.SearchURL() + "?page=0", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(tt.url)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.url, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserRetryRespectsCallerDeadline(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+	srv.Inject(userPath, codewarstest.RateLimited(2*time.Second))
+
+	client := srv.NewClient(codewars.WithRetryPolicy(retryPolicy()))
+
+	// Общий запрос получает дедлайн вызывающего, поэтому повтор через 2s
+	// не начинается, и 429 возвращается сразу, а не по истечении дедлайна
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetUser(ctx, "some_user")
+	if !errors.Is(err, codewars.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetUser took %v, want immediate failure", elapsed)
+	}
+	if hits := srv.Hits(userPath); hits != 1 {
+		t.Errorf("hits = %d, want 1", hits)
+	}
+}
+
+func TestReplayMissIsNotUpstreamFailure(t *testing.T) {
+	clock := newFakeClock()
+	client := codewars.NewClient("https://www.codewars.com/api/v1",
+		codewars.WithMode(codewars.ModeReplay, t.TempDir()),
+		codewars.WithRetryPolicy(retryPolicy()),
+		codewars.WithCircuitBreaker(codewars.BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}),
+		codewars.WithClock(clock),
+		codewars.WithPrefill(false),
+	)
+
+	for range 3 {
+		_, err := client.GetUser(context.Background(), "some_user")
+		if !errors.Is(err, codewars.ErrFixtureNotFound) {
+			t.Fatalf("err = %v, want ErrFixtureNotFound", err)
+		}
+		if errors.Is(err, codewars.ErrUnavailable) {
+			t.Fatalf("err = %v must not be ErrUnavailable", err)
+		}
+	}
+
+	if waits := clock.Waits(); len(waits) != 0 {
+		t.Errorf("waits = %v, replay miss must not be retried", waits)
+	}
+	if state := client.BreakerStats().State; state != codewars.BreakerClosed {
+		t.Errorf("breaker state = %s, want closed", state)
+	}
+}
+
+func TestGetUserWithRevalidateBypassesFreshCache(t *testing.T) {
+	srv := codewarstest.NewServer(codewarstest.DefaultFixtures())
+	defer srv.Close()
+
+	clock := newFakeClock()
+	client := srv.NewClient(
+		codewars.WithClock(clock),
+		codewars.WithCache(codewars.CacheConfig{
+			MaxEntries: 10,
+			TTL:        map[codewars.Endpoint]time.Duration{codewars.EndpointUser: time.Minute},
+		}),
+	)
+	ctx := context.Background()
+
+	first, err := client.GetUser(ctx, "some_user")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	fetchedAt := clock.Now()
+	if !first.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("FetchedAt = %v, want %v", first.FetchedAt, fetchedAt)
+	}
+
+	// Ответ из кэша сохраняет время исходного запроса
+	clock.Advance(30 * time.Second)
+	cached, err := client.GetUser(ctx, "some_user")
+	if err != nil {
+		t.Fatalf("GetUser from cache: %v", err)
+	}
+	if !cached.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("cached FetchedAt = %v, want %v", cached.FetchedAt, fetchedAt)
+	}
+	if hits := srv.Hits(userPath); hits != 1 {
+		t.Fatalf("hits = %d, want 1", hits)
+	}
+
+	// Перепроверка идет к серверу, хотя запись еще свежая
+	refreshed, err := client.GetUser(codewars.WithRevalidate(ctx), "some_user")
+	if err != nil {
+		t.Fatalf("GetUser with revalidate: %v", err)
+	}
+	if hits := srv.Hits(userPath); hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+	if !refreshed.FetchedAt.Equal(clock.Now()) {
+		t.Errorf("refreshed FetchedAt = %v, want %v", refreshed.FetchedAt, clock.Now())
+	}
+	if revalidated := client.Stats().Cache.Revalidated; revalidated != 1 {
+		t.Errorf("revalidated = %d, want 1 (conditional request)", revalidated)
+	}
+}
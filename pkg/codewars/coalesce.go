@@ -0,0 +1,162 @@
+package codewars
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// flightTimeout ограничивает общий запрос, если у ожидающего вызова нет дедлайна
+const flightTimeout = time.Minute
+
+// CoalesceStats - счетчики объединения одинаковых запросов
+type CoalesceStats struct {
+	InFlight int   `json:"in_flight"` // Запросов к Codewars, выполняющихся сейчас
+	Shared   int64 `json:"shared"`    // Вызовов, получивших результат чужого запроса
+}
+
+// flight - выполняющийся запрос, результат которого ждут несколько вызовов
+type flight struct {
+	done    chan struct{}
+	res     *fetched
+	err     error
+	waiters int
+	ctx     *flightContext
+}
+
+// flightContext - контекст общего запроса. Он хранит значения первого вызова,
+// но не его отмену; дедлайн равен самому позднему дедлайну ожидающих вызовов
+// и сдвигается, когда присоединяется вызов с более поздним дедлайном
+type flightContext struct {
+	context.Context
+	cancel context.CancelCauseFunc
+
+	mu       sync.Mutex
+	deadline time.Time
+	timer    *time.Timer
+}
+
+func newFlightContext(ctx context.Context) *flightContext {
+	base, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	fc := &flightContext{Context: base, cancel: cancel, deadline: waiterDeadline(ctx)}
+	fc.timer = time.AfterFunc(time.Until(fc.deadline), func() {
+		cancel(context.DeadlineExceeded)
+	})
+	return fc
+}
+
+// waiterDeadline - сколько готов ждать вызов; без дедлайна - не дольше flightTimeout
+func waiterDeadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(flightTimeout)
+}
+
+func (fc *flightContext) Deadline() (time.Time, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.deadline, true
+}
+
+// Err возвращает context.DeadlineExceeded, если истек дедлайн, как у context.WithDeadline
+func (fc *flightContext) Err() error {
+	if err := fc.Context.Err(); err != nil {
+		if cause := context.Cause(fc.Context); errors.Is(cause, context.DeadlineExceeded) {
+			return cause
+		}
+		return err
+	}
+	return nil
+}
+
+// extend сдвигает дедлайн, если вызову ctx нужно ждать дольше
+func (fc *flightContext) extend(ctx context.Context) {
+	deadline := waiterDeadline(ctx)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if !deadline.After(fc.deadline) || fc.Context.Err() != nil {
+		return
+	}
+	fc.deadline = deadline
+	fc.timer.Reset(time.Until(deadline))
+}
+
+func (fc *flightContext) stop() {
+	fc.timer.Stop()
+	fc.cancel(context.Canceled)
+}
+
+// coalescer объединяет одновременные запросы с одинаковым ключом в один.
+// Запрос выполняется в собственном контексте (flightContext): отмена одного
+// вызова не влияет на остальных, а запрос отменяется, только когда его
+// перестали ждать все или истек самый поздний из их дедлайнов
+type coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+	shared  atomic.Int64
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{flights: make(map[string]*flight)}
+}
+
+func (g *coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) (*fetched, error)) (*fetched, error) {
+	g.mu.Lock()
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		f.ctx.extend(ctx)
+		g.shared.Add(1)
+	} else {
+		f = &flight{done: make(chan struct{}), waiters: 1, ctx: newFlightContext(ctx)}
+		g.flights[key] = f
+
+		go func() {
+			defer f.ctx.stop()
+			f.res, f.err = fn(f.ctx)
+			g.forget(key, f)
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.res, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Результат больше никому не нужен: новые вызовы начнут свой запрос
+			f.ctx.stop()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *coalescer) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+func (g *coalescer) Stats() CoalesceStats {
+	g.mu.Lock()
+	inFlight := len(g.flights)
+	g.mu.Unlock()
+
+	return CoalesceStats{
+		InFlight: inFlight,
+		Shared:   g.shared.Load(),
+	}
+}
@@ -0,0 +1,59 @@
+package codewarstest
+
+import (
+	"SolverAPI/internal/model"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed fixtures/default.json
+var defaultFixtures []byte
+
+// Fixtures - данные, которые отдает фейковый сервер
+type Fixtures struct {
+	Users     map[string]model.CodewarsUser         `json:"users"`
+	Katas     map[string]model.CodewarsKata         `json:"katas"`
+	Completed map[string][]model.CompletedChallenge `json:"completed"`
+	Authored  map[string][]model.AuthoredChallenge  `json:"authored"`
+}
+
+// DefaultFixtures возвращает встроенный набор: двух пользователей из одного клана
+// и несколько задач, которых достаточно для заполнения буфера
+func DefaultFixtures() Fixtures {
+	f, err := parseFixtures(defaultFixtures)
+	if err != nil {
+		panic(fmt.Sprintf("codewarstest: broken default fixtures: %v", err))
+	}
+	return f
+}
+
+// LoadFixtures читает набор данных из JSON-файла того же формата, что fixtures/default.json
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	return parseFixtures(data)
+}
+
+func parseFixtures(data []byte) (Fixtures, error) {
+	var f Fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Fixtures{}, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	if f.Users == nil {
+		f.Users = map[string]model.CodewarsUser{}
+	}
+	if f.Katas == nil {
+		f.Katas = map[string]model.CodewarsKata{}
+	}
+	if f.Completed == nil {
+		f.Completed = map[string][]model.CompletedChallenge{}
+	}
+	if f.Authored == nil {
+		f.Authored = map[string][]model.AuthoredChallenge{}
+	}
+	return f, nil
+}
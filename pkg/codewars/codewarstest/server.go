@@ -0,0 +1,445 @@
+// Package codewarstest поднимает in-process заменитель Codewars на httptest.Server,
+// чтобы сервисы поверх codewars.Client можно было проверять без сети.
+package codewarstest
+
+import (
+	"SolverAPI/internal/model"
+	"SolverAPI/pkg/codewars"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiPrefix       = "/api/v1"
+	searchPath      = "/kata/search"
+	defaultPageSize = 200 // Столько же отдает Codewars на страницу completed
+	// Столько задач на странице поиска
+	defaultSearchPageSize = 30
+)
+
+// Fault описывает сбой, который сервер вернет вместо нормального ответа
+type Fault struct {
+	Status     int           // HTTP-статус ответа (404, 429, 500...); 0 - оставить 200
+	RetryAfter time.Duration // Заголовок Retry-After, если больше нуля
+	Malformed  bool          // Отдать битый JSON со статусом 200
+	Latency    time.Duration // Дополнительная задержка перед ответом
+	Times      int           // Сколько запросов затронуть; 0 - все
+}
+
+// Готовые сценарии сбоев
+var (
+	NotFound    = Fault{Status: http.StatusNotFound}
+	ServerError = Fault{Status: http.StatusInternalServerError}
+	Malformed   = Fault{Malformed: true}
+)
+
+// RateLimited возвращает сбой 429 с заголовком Retry-After
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+type faultRule struct {
+	prefix string
+	fault  Fault
+	used   int
+}
+
+// Server - фейковый Codewars. Безопасен для конкурентного использования
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	fixtures Fixtures
+	faults   []*faultRule
+	latency  time.Duration
+	pageSize int
+	hits     map[string]int
+
+	searchPageSize int
+}
+
+// NewServer запускает сервер с указанными данными. Остановить его нужно через Close
+func NewServer(f Fixtures) *Server {
+	s := &Server{
+		fixtures: f,
+		pageSize: defaultPageSize,
+		hits:     make(map[string]int),
+
+		searchPageSize: defaultSearchPageSize,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPrefix+"/users/{username}", s.handleUser)
+	mux.HandleFunc("GET "+apiPrefix+"/users/{username}/code-challenges/completed", s.handleCompleted)
+	mux.HandleFunc("GET "+apiPrefix+"/users/{username}/code-challenges/authored", s.handleAuthored)
+	mux.HandleFunc("GET "+apiPrefix+"/code-challenges", s.handleKataList)
+	mux.HandleFunc("GET "+apiPrefix+"/code-challenges/{id}", s.handleKata)
+	mux.HandleFunc("GET "+searchPath, s.handleSearch)
+	mux.HandleFunc("GET "+searchPath+"/{language}", s.handleSearch)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// APIURL - адрес для codewars.NewClient
+func (s *Server) APIURL() string { return s.URL + apiPrefix }
+
+// SearchURL - адрес для codewars.WithSearchURL
+func (s *Server) SearchURL() string { return s.URL + searchPath }
+
+// NewClient создает клиента, полностью направленного на этот сервер.
+// Буфер не заполняется в фоне, повторы отключены; opts применяются поверх
+func (s *Server) NewClient(opts ...codewars.Option) *codewars.Client {
+	base := []codewars.Option{
+		codewars.WithHTTPClient(s.Client()),
+		codewars.WithSearchURL(s.SearchURL()),
+		codewars.WithPrefill(false),
+		codewars.WithRetryPolicy(codewars.RetryPolicy{MaxAttempts: 1}),
+	}
+	return codewars.NewClient(s.APIURL(), append(base, opts...)...)
+}
+
+// Inject добавляет сбой для всех запросов, путь которых начинается с prefix
+// (например "/api/v1/users/some_user" или "/kata/search")
+func (s *Server) Inject(prefix string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &faultRule{prefix: prefix, fault: f})
+}
+
+// SetLatency задает задержку для всех ответов
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetPageSize задает размер страницы списка решенных задач. n должно быть больше нуля
+func (s *Server) SetPageSize(n int) {
+	if n < 1 {
+		panic(fmt.Sprintf("codewarstest: page size must be positive, got %d", n))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// SetSearchPageSize задает число задач на странице поиска. n должно быть больше нуля
+func (s *Server) SetSearchPageSize(n int) {
+	if n < 1 {
+		panic(fmt.Sprintf("codewarstest: search page size must be positive, got %d", n))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchPageSize = n
+}
+
+// Reset убирает все сбои и задержки и обнуляет счетчики запросов
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.latency = 0
+	s.hits = make(map[string]int)
+}
+
+// Hits возвращает число запросов к пути (без query-строки)
+func (s *Server) Hits(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+// Update позволяет изменить данные сервера на лету
+func (s *Server) Update(fn func(f *Fixtures)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.fixtures)
+}
+
+// pickFault находит первый активный сбой для пути и отмечает его использование
+func (s *Server) pickFault(path string) (Fault, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hits[path]++
+	for _, rule := range s.faults {
+		if !strings.HasPrefix(path, rule.prefix) {
+			continue
+		}
+		if rule.fault.Times > 0 && rule.used >= rule.fault.Times {
+			continue
+		}
+		rule.used++
+		return rule.fault, s.latency, true
+	}
+	return Fault{}, s.latency, false
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault, latency, ok := s.pickFault(r.URL.Path)
+		if ok {
+			latency += fault.Latency
+		}
+
+		if latency > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(latency):
+			}
+		}
+
+		if !ok || (fault.Status == 0 && !fault.Malformed) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if fault.RetryAfter > 0 {
+			seconds := int((fault.RetryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		if fault.Malformed {
+			w.Header().Set("Content-Type", "application/json")
+			status := fault.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"success": tru`)
+			return
+		}
+		writeJSON(w, fault.Status, map[string]any{
+			"success": false,
+			"reason":  http.StatusText(fault.Status),
+		})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeCacheableJSON отдает ответ с ETag и поддерживает If-None-Match
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, _ := json.Marshal(v)
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]any{"success": false, "reason": "not found"})
+}
+
+// pageParam читает номер страницы из query. Пустое значение - первая страница,
+// отрицательное или нечисловое - 400, иначе срез страницы выйдет за границы
+func pageParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("page")
+	if raw == "" {
+		return 0, true
+	}
+	page, err := strconv.Atoi(raw)
+	if err != nil || page < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "reason": "invalid page"})
+		return 0, false
+	}
+	return page, true
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	user, ok := s.fixtures.Users[r.PathValue("username")]
+	s.mu.Unlock()
+
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeCacheableJSON(w, r, user)
+}
+
+func (s *Server) handleCompleted(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	page, ok := pageParam(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	_, known := s.fixtures.Users[username]
+	items := s.fixtures.Completed[username]
+	pageSize := s.pageSize
+	s.mu.Unlock()
+
+	if !known {
+		notFound(w)
+		return
+	}
+
+	totalPages := (len(items) + pageSize - 1) / pageSize
+	start := min(page*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"totalPages": totalPages,
+		"totalItems": len(items),
+		"data":       items[start:end],
+	})
+}
+
+func (s *Server) handleAuthored(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+
+	s.mu.Lock()
+	_, known := s.fixtures.Users[username]
+	items := s.fixtures.Authored[username]
+	s.mu.Unlock()
+
+	if !known {
+		notFound(w)
+		return
+	}
+	if items == nil {
+		items = []model.AuthoredChallenge{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+func (s *Server) handleKata(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	kata, ok := s.fixtures.Katas[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeCacheableJSON(w, r, kata)
+}
+
+func (s *Server) handleKataList(w http.ResponseWriter, r *http.Request) {
+	ids := s.kataIDs()
+	data := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		data = append(data, map[string]string{"id": id})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// handleSearch отдает HTML, похожий на страницу поиска codewars.com.
+// Поддерживает язык в пути, q, r[], tags, beta и page
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, ok := pageParam(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	var matched []model.CodewarsKata
+	for _, id := range s.sortedKataIDsLocked() {
+		kata := s.fixtures.Katas[id]
+		if matchesSearch(kata, r.PathValue("language"), query) {
+			matched = append(matched, kata)
+		}
+	}
+	pageSize := s.searchPageSize
+	s.mu.Unlock()
+
+	start := min(page*pageSize, len(matched))
+	end := min(start+pageSize, len(matched))
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><body><div class=\"items-list\">\n")
+	for _, kata := range matched[start:end] {
+		writeSearchItem(&b, kata)
+	}
+	b.WriteString("</div></body></html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, b.String())
+}
+
+func writeSearchItem(b *strings.Builder, kata model.CodewarsKata) {
+	fmt.Fprintf(b, "<div class=\"list-item-kata bg-ui-section\" id=\"%s\" data-id=\"%s\">", kata.ID, kata.ID)
+	if kata.Rank.ID != nil {
+		fmt.Fprintf(b, "<div class=\"small-hex\"><div class=\"inner-small-hex\"><span>%s</span></div></div>",
+			html.EscapeString(kata.Rank.Name))
+	}
+	fmt.Fprintf(b, "<a href=\"/kata/%s/train/%s\">%s</a>", kata.ID, firstLanguage(kata), html.EscapeString(kata.Name))
+	b.WriteString("<div class=\"languages\">")
+	for _, lang := range kata.Languages {
+		fmt.Fprintf(b, "<a href=\"/kata/%s/train/%s\" data-language=\"%s\"></a>", kata.ID, lang, lang)
+	}
+	b.WriteString("</div></div>\n")
+}
+
+func firstLanguage(kata model.CodewarsKata) string {
+	if len(kata.Languages) == 0 {
+		return ""
+	}
+	return kata.Languages[0]
+}
+
+func matchesSearch(kata model.CodewarsKata, language string, query url.Values) bool {
+	if language != "" && !slices.Contains(kata.Languages, language) {
+		return false
+	}
+	if q := strings.ToLower(query.Get("q")); q != "" && !strings.Contains(strings.ToLower(kata.Name), q) {
+		return false
+	}
+	if ranks := query["r[]"]; len(ranks) > 0 {
+		if kata.Rank.ID == nil || !slices.Contains(ranks, strconv.Itoa(*kata.Rank.ID)) {
+			return false
+		}
+	}
+	if tags := query.Get("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if !slices.Contains(kata.Tags, tag) {
+				return false
+			}
+		}
+	}
+	switch query.Get("beta") {
+	case "true":
+		return kata.Rank.ID == nil
+	case "false":
+		return kata.Rank.ID != nil
+	}
+	return true
+}
+
+func (s *Server) kataIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedKataIDsLocked()
+}
+
+func (s *Server) sortedKataIDsLocked() []string {
+	ids := make([]string, 0, len(s.fixtures.Katas))
+	for id := range s.fixtures.Katas {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,109 @@
+package codewars
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ошибки клиента Codewars. Сервисы оборачивают их через %w,
+// поэтому проверять их нужно через errors.Is / errors.As.
+var (
+	ErrNotFound    = errors.New("codewars: resource not found")
+	ErrRateLimited = errors.New("codewars: rate limited")
+	ErrUnavailable = errors.New("codewars: upstream unavailable")
+	ErrDecode      = errors.New("codewars: failed to decode response")
+)
+
+// StatusError - неожиданный HTTP-статус ответа Codewars
+type StatusError struct {
+	StatusCode int
+	URL        string
+	RetryAfter time.Duration // Заголовок Retry-After, если он был (обычно с 503)
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("codewars: unexpected status code %d for %s", e.StatusCode, e.URL)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// RateLimitError возвращается на ответ 429. RetryAfter равен нулю,
+// если Codewars не прислал заголовок Retry-After
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("codewars: rate limited, retry after %s", e.RetryAfter)
+	}
+	return "codewars: rate limited"
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// DecodeError - ответ Codewars не удалось разобрать
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("codewars: failed to decode response from %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// checkStatus превращает статус ответа в типизированную ошибку
+func checkStatus(resp *http.Response, now time.Time) error {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now)}
+	default:
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			URL:        resp.Request.URL.String(),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+		}
+	}
+}
+
+// parseRetryAfter поддерживает оба формата заголовка: секунды и HTTP-дату
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
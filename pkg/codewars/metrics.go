@@ -0,0 +1,208 @@
+package codewars
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Границы корзин гистограммы задержек, в секундах
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// endpointOther - метка запросов, выполненных без указания эндпоинта
+const endpointOther Endpoint = "other"
+
+type endpointCtxKey struct{}
+
+// withEndpoint помечает запрос логическим эндпоинтом для метрик
+func withEndpoint(ctx context.Context, endpoint Endpoint) context.Context {
+	return context.WithValue(ctx, endpointCtxKey{}, endpoint)
+}
+
+func endpointFrom(ctx context.Context) Endpoint {
+	if endpoint, ok := ctx.Value(endpointCtxKey{}).(Endpoint); ok {
+		return endpoint
+	}
+	return endpointOther
+}
+
+// LatencyBucket - число запросов с задержкой не больше LE секунд
+type LatencyBucket struct {
+	LE    float64 `json:"le"`
+	Count int64   `json:"count"`
+}
+
+// LatencyHistogram - кумулятивная гистограмма задержек до получения заголовков ответа
+type LatencyHistogram struct {
+	Buckets    []LatencyBucket `json:"buckets"`
+	Count      int64           `json:"count"`
+	SumSeconds float64         `json:"sum_seconds"`
+}
+
+// EndpointStats - метрики запросов к одному эндпоинту Codewars
+type EndpointStats struct {
+	Requests  int64            `json:"requests"`
+	Errors    int64            `json:"errors"` // Запросов без ответа (сеть, таймаут)
+	Statuses  map[string]int64 `json:"statuses"`
+	BytesRead int64            `json:"bytes_read"`
+	Latency   LatencyHistogram `json:"latency"`
+}
+
+type endpointMetrics struct {
+	requests  int64
+	errors    int64
+	statuses  map[int]int64
+	bytesRead int64
+	buckets   []int64 // Некумулятивные счетчики; последний - +Inf
+	sum       float64
+}
+
+// transportMetrics собирает метрики по эндпоинтам
+type transportMetrics struct {
+	mu        sync.Mutex
+	endpoints map[Endpoint]*endpointMetrics
+}
+
+func newTransportMetrics() *transportMetrics {
+	return &transportMetrics{endpoints: make(map[Endpoint]*endpointMetrics)}
+}
+
+func (m *transportMetrics) endpointLocked(endpoint Endpoint) *endpointMetrics {
+	em, ok := m.endpoints[endpoint]
+	if !ok {
+		em = &endpointMetrics{
+			statuses: make(map[int]int64),
+			buckets:  make([]int64, len(latencyBuckets)+1),
+		}
+		m.endpoints[endpoint] = em
+	}
+	return em
+}
+
+func (m *transportMetrics) observe(endpoint Endpoint, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	em := m.endpointLocked(endpoint)
+	em.requests++
+	if status == 0 {
+		em.errors++
+	} else {
+		em.statuses[status]++
+	}
+
+	seconds := latency.Seconds()
+	em.sum += seconds
+	i := 0
+	for i < len(latencyBuckets) && seconds > latencyBuckets[i] {
+		i++
+	}
+	em.buckets[i]++
+}
+
+func (m *transportMetrics) addBytes(endpoint Endpoint, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpointLocked(endpoint).bytesRead += n
+}
+
+func (m *transportMetrics) Stats() map[Endpoint]EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[Endpoint]EndpointStats, len(m.endpoints))
+	for endpoint, em := range m.endpoints {
+		stats := EndpointStats{
+			Requests:  em.requests,
+			Errors:    em.errors,
+			Statuses:  make(map[string]int64, len(em.statuses)),
+			BytesRead: em.bytesRead,
+			Latency: LatencyHistogram{
+				Buckets:    make([]LatencyBucket, len(latencyBuckets)),
+				Count:      em.requests,
+				SumSeconds: em.sum,
+			},
+		}
+		for status, n := range em.statuses {
+			stats.Statuses[strconv.Itoa(status)] = n
+		}
+		var cumulative int64
+		for i, le := range latencyBuckets {
+			cumulative += em.buckets[i]
+			stats.Latency.Buckets[i] = LatencyBucket{LE: le, Count: cumulative}
+		}
+		result[endpoint] = stats
+	}
+	return result
+}
+
+// instrumentedTransport записывает задержку, статус и объем ответа каждого
+// запроса к Codewars и пишет о нем структурированный лог
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	metrics *transportMetrics
+	logger  *slog.Logger
+	clock   Clock
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointFrom(req.Context())
+	start := t.clock.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	latency := t.clock.Now().Sub(start)
+
+	if err != nil {
+		t.metrics.observe(endpoint, 0, latency)
+		t.logger.Warn("codewars request failed",
+			"endpoint", endpoint,
+			"method", req.Method,
+			"url", req.URL.String(),
+			"duration", latency,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	t.metrics.observe(endpoint, resp.StatusCode, latency)
+	resp.Body = &countingBody{
+		ReadCloser: resp.Body,
+		onClose: func(n int64) {
+			t.metrics.addBytes(endpoint, n)
+			t.logger.Info("codewars request",
+				"endpoint", endpoint,
+				"method", req.Method,
+				"url", req.URL.String(),
+				"status", resp.StatusCode,
+				"duration", latency,
+				"bytes", n,
+			)
+		},
+	}
+	return resp, nil
+}
+
+// countingBody считает прочитанные байты и сообщает итог при закрытии
+type countingBody struct {
+	io.ReadCloser
+	n       atomic.Int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.onClose(b.n.Load()) })
+	return err
+}
@@ -0,0 +1,170 @@
+package codewars
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultSearchURL = "https://www.codewars.com/kata/search"
+	// Поиск задач отдается только браузерам, поэтому по умолчанию имитируем браузер
+	defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+)
+
+// Clock - источник времени клиента. Подменяется в тестах
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Option настраивает Client при создании
+type Option func(*Client)
+
+// WithRetryPolicy задает политику повторов запросов
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		if p.MaxAttempts < 1 {
+			p.MaxAttempts = 1
+		}
+		c.retry = p
+	}
+}
+
+// WithRateLimit ограничивает частоту исходящих запросов.
+// Лимит общий для всех методов клиента
+func WithRateLimit(cfg RateLimit) Option {
+	return func(c *Client) {
+		c.rateLimit = cfg
+	}
+}
+
+// WithCache включает кэш ответов с TTL по эндпоинтам
+func WithCache(cfg CacheConfig) Option {
+	return func(c *Client) {
+		c.cacheConfig = cfg
+	}
+}
+
+// WithCircuitBreaker включает circuit breaker для запросов к Codewars
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(c *Client) {
+		c.breakerConfig = cfg
+	}
+}
+
+// WithHTTPClient задает http.Client для запросов. Клиент не изменяется:
+// если заданы WithTransport или WithTimeout, они применяются к копии
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.baseHTTPClient = hc
+	}
+}
+
+// WithTransport задает http.RoundTripper для запросов
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithTimeout задает таймаут одной попытки запроса
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent задает заголовок User-Agent для всех запросов
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithSearchURL задает адрес страницы поиска задач (по умолчанию codewars.com/kata/search)
+func WithSearchURL(u string) Option {
+	return func(c *Client) {
+		c.searchURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithLogger задает логгер клиента
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithClock задает источник времени
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithPrefill включает или отключает заполнение буфера задач
+// сразу при Start (по умолчанию включено). Без этого буфер заполнится
+// по таймеру или при первом запросе случайной задачи
+func WithPrefill(enabled bool) Option {
+	return func(c *Client) {
+		c.prefill = enabled
+	}
+}
+
+// WithBufferInterval задает период фонового обновления буфера задач (по умолчанию 1 час)
+func WithBufferInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.bufferInterval = d
+	}
+}
+
+// WithMode задает режим транспорта: live, record (запись ответов
+// в fixturesDir) или replay (ответы только из fixturesDir, без сети)
+func WithMode(mode Mode, fixturesDir string) Option {
+	return func(c *Client) {
+		c.mode = mode
+		c.fixturesDir = fixturesDir
+	}
+}
+
+// buildHTTPClient собирает итоговый http.Client из опций
+func (c *Client) buildHTTPClient() *http.Client {
+	var hc http.Client
+	if c.baseHTTPClient != nil {
+		hc = *c.baseHTTPClient
+	} else {
+		hc.Timeout = defaultTimeout
+	}
+	if c.timeout > 0 {
+		hc.Timeout = c.timeout
+	}
+	if c.transport != nil {
+		hc.Transport = c.transport
+	}
+
+	next := hc.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	switch c.mode {
+	case ModeRecord:
+		next = &recordingTransport{next: next, store: &fixtureStore{dir: c.fixturesDir}, client: c}
+	case ModeReplay:
+		next = &replayTransport{store: &fixtureStore{dir: c.fixturesDir}}
+	}
+	hc.Transport = &instrumentedTransport{
+		next:    next,
+		metrics: c.metrics,
+		logger:  c.logger,
+		clock:   c.clock,
+	}
+	return &hc
+}
@@ -0,0 +1,129 @@
+package codewars
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit задает общий бюджет исходящих запросов к Codewars.
+// Rate <= 0 отключает ограничение
+type RateLimit struct {
+	Rate  float64 // Запросов в секунду
+	Burst int     // Сколько запросов можно сделать подряд без ожидания
+}
+
+// LimiterStats - состояние лимитера для метрик
+type LimiterStats struct {
+	Rate        float64       `json:"rate"`
+	Burst       int           `json:"burst"`
+	CurrentWait time.Duration `json:"current_wait_ns"` // Сколько ждал бы новый запрос прямо сейчас
+	Waiting     int64         `json:"waiting"`         // Запросов в ожидании токена
+	Rejected    int64         `json:"rejected"`        // Запросов, не дождавшихся токена до дедлайна
+}
+
+// rateLimiter - token bucket, общий для всех запросов клиента
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	clock Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	waiting  atomic.Int64
+	rejected atomic.Int64
+}
+
+func newRateLimiter(cfg RateLimit, clock Clock) *rateLimiter {
+	if cfg.Rate <= 0 {
+		return nil
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	return &rateLimiter{
+		rate:   cfg.Rate,
+		burst:  float64(cfg.Burst),
+		clock:  clock,
+		tokens: float64(cfg.Burst),
+		last:   clock.Now(),
+	}
+}
+
+// refill начисляет токены за прошедшее время. Вызывается под mu
+func (l *rateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+		l.last = now
+	}
+}
+
+// Wait резервирует токен и ждет его освобождения. Если токен не успеет
+// освободиться до дедлайна контекста, запрос отклоняется сразу
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		l.tokens++ // Возвращаем зарезервированный токен
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return fmt.Errorf("codewars: rate limiter wait %s exceeds context deadline: %w", wait, context.DeadlineExceeded)
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return ctx.Err()
+	case <-l.clock.After(wait):
+		return nil
+	}
+}
+
+func (l *rateLimiter) Stats() LimiterStats {
+	if l == nil {
+		return LimiterStats{}
+	}
+
+	l.mu.Lock()
+	l.refill(l.clock.Now())
+	var wait time.Duration
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	return LimiterStats{
+		Rate:        l.rate,
+		Burst:       int(l.burst),
+		CurrentWait: wait,
+		Waiting:     l.waiting.Load(),
+		Rejected:    l.rejected.Load(),
+	}
+}
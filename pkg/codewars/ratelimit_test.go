@@ -0,0 +1,155 @@
+package codewars
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock - часы для тестов пакета: время двигается только через Advance,
+// After срабатывает сразу и запоминает запрошенную паузу
+type manualClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Now()}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// waitsSince возвращает паузы, запрошенные после первых n вызовов After
+func (c *manualClock) waitsSince(n int) []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits[n:]...)
+}
+
+func (c *manualClock) waitCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waits)
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	type step struct {
+		advance  time.Duration // Сдвиг часов перед вызовом
+		deadline time.Duration // Дедлайн контекста от текущего времени, 0 - без дедлайна
+		wantWait time.Duration // Ожидаемая пауза, 0 - без ожидания
+		wantErr  bool          // Ожидается отказ из-за дедлайна
+	}
+
+	tests := []struct {
+		name  string
+		limit RateLimit
+		steps []step
+	}{
+		{
+			name:  "burst passes without waiting",
+			limit: RateLimit{Rate: 1, Burst: 3},
+			steps: []step{{}, {}, {}},
+		},
+		{
+			name:  "requests over burst wait for refill",
+			limit: RateLimit{Rate: 2, Burst: 1},
+			steps: []step{{}, {wantWait: 500 * time.Millisecond}, {wantWait: time.Second}},
+		},
+		{
+			name:  "idle time refills up to burst",
+			limit: RateLimit{Rate: 1, Burst: 2},
+			steps: []step{{}, {}, {advance: time.Hour}, {}, {wantWait: time.Second}},
+		},
+		{
+			name:  "burst below one is treated as one",
+			limit: RateLimit{Rate: 1, Burst: 0},
+			steps: []step{{}, {wantWait: time.Second}},
+		},
+		{
+			name:  "wait beyond deadline is rejected and token returned",
+			limit: RateLimit{Rate: 1, Burst: 1},
+			steps: []step{
+				{},
+				{deadline: 100 * time.Millisecond, wantErr: true},
+				{wantWait: time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newManualClock()
+			l := newRateLimiter(tt.limit, clock)
+
+			rejected := 0
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				ctx := context.Background()
+				if s.deadline > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithDeadline(ctx, clock.Now().Add(s.deadline))
+					defer cancel()
+				}
+
+				before := clock.waitCount()
+				err := l.Wait(ctx)
+				if s.wantErr {
+					rejected++
+					if !errors.Is(err, context.DeadlineExceeded) {
+						t.Fatalf("step %d: err = %v, want DeadlineExceeded", i, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d: Wait: %v", i, err)
+				}
+
+				waits := clock.waitsSince(before)
+				var got time.Duration
+				if len(waits) > 0 {
+					got = waits[0]
+				}
+				if got != s.wantWait {
+					t.Errorf("step %d: wait = %v, want %v", i, got, s.wantWait)
+				}
+			}
+
+			if stats := l.Stats(); stats.Rejected != int64(rejected) {
+				t.Errorf("rejected = %d, want %d", stats.Rejected, rejected)
+			}
+		})
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	if l := newRateLimiter(RateLimit{Rate: 0, Burst: 5}, newManualClock()); l != nil {
+		t.Fatal("limiter with Rate 0 must be disabled")
+	}
+	var l *rateLimiter
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait: %v", err)
+	}
+}
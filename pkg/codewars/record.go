@@ -0,0 +1,314 @@
+package codewars
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode - режим работы транспорта клиента
+type Mode string
+
+const (
+	// ModeLive - обычные запросы к Codewars
+	ModeLive Mode = "live"
+	// ModeRecord - запросы к Codewars с записью ответов в файлы
+	ModeRecord Mode = "record"
+	// ModeReplay - ответы только из записанных файлов, без сети
+	ModeReplay Mode = "replay"
+)
+
+// ErrFixtureNotFound - в режиме replay для запроса нет записанного ответа
+var ErrFixtureNotFound = errors.New("codewars: recorded response not found")
+
+// Fixture - записанный ответ Codewars
+type Fixture struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+// FixtureName возвращает имя файла записи для запроса.
+// Ключ - метод и путь с query без схемы и хоста, поэтому записи
+// подходят и для реального Codewars, и для локальной подмены
+func FixtureName(method, rawURL string) string {
+	sum := sha256.Sum256([]byte(method + " " + fixtureKey(rawURL)))
+	return strings.ToLower(method) + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+func fixtureKey(rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest := rawURL[i+3:]
+		if j := strings.IndexByte(rest, '/'); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	return rawURL
+}
+
+// fixtureStore читает и пишет записи ответов в каталоге
+type fixtureStore struct {
+	dir string
+}
+
+func (s *fixtureStore) path(method, rawURL string) string {
+	return filepath.Join(s.dir, FixtureName(method, rawURL))
+}
+
+func (s *fixtureStore) load(method, rawURL string) (*Fixture, error) {
+	data, err := os.ReadFile(s.path(method, rawURL))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s", ErrFixtureNotFound, method, rawURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture for %s %s: %w", method, rawURL, err)
+	}
+	return &f, nil
+}
+
+func (s *fixtureStore) save(f *Fixture) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Пишем через временный файл, чтобы replay не прочитал запись наполовину
+	tmp, err := os.CreateTemp(s.dir, ".fixture-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(f.Method, f.URL))
+}
+
+// recordingTransport пишет ответы Codewars в файлы.
+// Не записываются 304, 429 и 5xx - они не описывают ресурс
+type recordingTransport struct {
+	next   http.RoundTripper
+	store  *fixtureStore
+	client *Client
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !recordable(resp.StatusCode) {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	fixture := &Fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Status:     resp.StatusCode,
+		Header:     header,
+		Body:       string(body),
+		RecordedAt: t.client.clock.Now().UTC(),
+	}
+	if err := t.store.save(fixture); err != nil {
+		t.client.logger.Warn("failed to record codewars response", "url", fixture.URL, "error", err)
+	}
+
+	return resp, nil
+}
+
+func recordable(status int) bool {
+	return status != http.StatusNotModified &&
+		status != http.StatusTooManyRequests &&
+		status < http.StatusInternalServerError
+}
+
+// replayTransport отдает ответы только из записанных файлов
+type replayTransport struct {
+	store *fixtureStore
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, err := t.store.load(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	header := f.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// driftDetector сообщает о полях ответа, которых нет в моделях.
+// Каждое поле попадает в лог один раз
+type driftDetector struct {
+	mu       sync.Mutex
+	reported map[string]bool
+}
+
+func newDriftDetector() *driftDetector {
+	return &driftDetector{reported: make(map[string]bool)}
+}
+
+// check сравнивает JSON-ответ с типом v и возвращает новые неизвестные поля
+func (d *driftDetector) check(endpoint Endpoint, body []byte, v any) []string {
+	var raw any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+
+	unknown := unknownFields(raw, reflect.TypeOf(v), "")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var fresh []string
+	for _, field := range unknown {
+		key := string(endpoint) + ":" + field
+		if !d.reported[key] {
+			d.reported[key] = true
+			fresh = append(fresh, field)
+		}
+	}
+	sort.Strings(fresh)
+	return fresh
+}
+
+// unknownFields возвращает пути полей JSON, которые не декодируются в тип t.
+// Имена сравниваются без учета регистра, как в encoding/json
+func unknownFields(raw any, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := raw.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			seen := make(map[string]bool)
+			var result []string
+			for _, item := range value {
+				for _, field := range unknownFields(item, t.Elem(), prefix+"*.") {
+					if !seen[field] {
+						seen[field] = true
+						result = append(result, field)
+					}
+				}
+			}
+			return result
+		case reflect.Struct:
+			fields := jsonFields(t)
+			var result []string
+			for key, item := range value {
+				field, ok := fields[strings.ToLower(key)]
+				if !ok {
+					result = append(result, prefix+key)
+					continue
+				}
+				result = append(result, unknownFields(item, field, prefix+key+".")...)
+			}
+			return result
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			// Поле, неизвестное в нескольких элементах, сообщается один раз
+			seen := make(map[string]bool)
+			var result []string
+			for _, item := range value {
+				for _, field := range unknownFields(item, t.Elem(), prefix+"[].") {
+					if !seen[field] {
+						seen[field] = true
+						result = append(result, field)
+					}
+				}
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+// jsonFields возвращает поля структуры по имени в JSON (в нижнем регистре),
+// включая поля встроенных структур
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
@@ -0,0 +1,77 @@
+package codewars
+
+import (
+	"slices"
+	"testing"
+)
+
+type driftInner struct {
+	Name string `json:"name"`
+}
+
+type driftBase struct {
+	ID string `json:"id"`
+}
+
+type driftModel struct {
+	driftBase
+	Title  string                `json:"title,omitempty"`
+	Inner  *driftInner           `json:"inner"`
+	Items  []driftInner          `json:"items"`
+	Ranks  map[string]driftInner `json:"ranks"`
+	Skip   string                `json:"-"`
+	Plain  int
+	hidden string
+}
+
+func TestDriftDetectorCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"known fields", `{"id":"1","title":"t","inner":{"name":"n"},"Plain":1}`, nil},
+		{"names are case insensitive", `{"ID":"1","TITLE":"t","plain":1}`, nil},
+		{"unknown top level fields are sorted", `{"zeta":1,"alpha":2,"id":"1"}`, []string{"alpha", "zeta"}},
+		{"nested pointer struct", `{"inner":{"name":"n","extra":true}}`, []string{"inner.extra"}},
+		{"null nested value", `{"inner":null}`, nil},
+		{"slice elements reported once", `{"items":[{"x":1},{"x":2,"y":3}]}`, []string{"items.[].x", "items.[].y"}},
+		{"map values", `{"ranks":{"go":{"score":1},"python":{"score":2}}}`, []string{"ranks.*.score"}},
+		{"ignored field is unknown", `{"Skip":"x"}`, []string{"Skip"}},
+		{"unexported field is unknown", `{"hidden":"x"}`, []string{"hidden"}},
+		{"type mismatch is not drift", `{"inner":[1,2]}`, nil},
+		{"invalid json", `{"id":`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDriftDetector().check(EndpointUser, []byte(tt.body), &driftModel{})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("check = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriftDetectorReportsOncePerEndpoint(t *testing.T) {
+	d := newDriftDetector()
+	body := []byte(`{"id":"1","extra":1}`)
+
+	steps := []struct {
+		endpoint Endpoint
+		body     []byte
+		want     []string
+	}{
+		{EndpointUser, body, []string{"extra"}},
+		{EndpointUser, body, nil},
+		{EndpointKata, body, []string{"extra"}},
+		{EndpointUser, []byte(`{"extra":1,"more":2}`), []string{"more"}},
+	}
+
+	for i, step := range steps {
+		got := d.check(step.endpoint, step.body, driftModel{})
+		if !slices.Equal(got, step.want) {
+			t.Errorf("step %d: check = %q, want %q", i, got, step.want)
+		}
+	}
+}
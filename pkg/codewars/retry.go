@@ -0,0 +1,95 @@
+package codewars
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy задает повторы идемпотентных GET-запросов к Codewars
+type RetryPolicy struct {
+	MaxAttempts int           // Всего попыток, включая первую; 1 отключает повторы
+	BaseDelay   time.Duration // Задержка перед первым повтором
+	MaxDelay    time.Duration // Верхняя граница задержки и допустимого Retry-After
+}
+
+// DefaultRetryPolicy возвращает политику, которая используется по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// delay возвращает паузу перед повтором номер attempt (начиная с 1).
+// Экспоненциальная задержка с jitter в диапазоне [d/2, d]; если сервер
+// прислал Retry-After, используется он. ok=false - ждать дольше MaxDelay нельзя
+func (p RetryPolicy) delay(attempt int, err error) (d time.Duration, ok bool) {
+	if retryAfter := retryAfterOf(err); retryAfter > 0 {
+		return retryAfter, retryAfter <= p.MaxDelay
+	}
+
+	d = p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if half := int64(d / 2); half > 0 {
+		d = time.Duration(half + rand.Int63n(half+1))
+	}
+	return d, true
+}
+
+// isRetryable - стоит ли повторять запрос после этой ошибки
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimited)
+}
+
+func retryAfterOf(err error) time.Duration {
+	var rateLimit *RateLimitError
+	if errors.As(err, &rateLimit) {
+		return rateLimit.RetryAfter
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// withRetry вызывает fn, пока она возвращает временную ошибку и есть попытки.
+// Повтор не делается, если пауза не укладывается в дедлайн контекста
+func (c *Client) withRetry(ctx context.Context, rawURL string, fn func() (*response, error)) (*response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := fn()
+		if err == nil || !isRetryable(err) || attempt >= c.retry.MaxAttempts {
+			return resp, err
+		}
+
+		wait, ok := c.retry.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && deadline.Sub(c.clock.Now()) < wait {
+			return nil, err
+		}
+
+		c.logger.Warn("codewars request failed, retrying",
+			"url", rawURL,
+			"attempt", attempt,
+			"max_attempts", c.retry.MaxAttempts,
+			"delay", wait,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.clock.After(wait):
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package codewars
+
+import (
+	"SolverAPI/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Сколько задач собирается в буфер по одному фильтру
+	defaultSearchLimit = 100
+	// Не больше стольких страниц поиска за один вызов
+	maxSearchPages = 10
+)
+
+var (
+	kataItemRe     = regexp.MustCompile(`<div[^>]*class="[^"]*list-item-kata[^"]*"`)
+	kataIDAttrRe   = regexp.MustCompile(`(?:data-id|id)="([a-f0-9]{24})"`)
+	kataLinkRe     = regexp.MustCompile(`/kata/([a-f0-9]{24})`)
+	kataNameRe     = regexp.MustCompile(`<a[^>]*href="/kata/[a-f0-9]{24}(?:/train/[^"]*)?"[^>]*>\s*([^<]+?)\s*</a>`)
+	kataRankRe     = regexp.MustCompile(`<span>\s*(\d)\s*(kyu|dan)\s*</span>`)
+	kataLanguageRe = regexp.MustCompile(`(?:data-language="|/train/|icon-moon-)([a-z0-9+#-]+)`)
+)
+
+// FilterKey возвращает каноничный ключ фильтра: одинаковые фильтры
+// дают одинаковый ключ. Пустой фильтр - пустая строка
+func FilterKey(f model.KataFilter) string {
+	values := searchValues(f)
+	if f.Language != "" {
+		values.Set("language", f.Language)
+	}
+	return values.Encode()
+}
+
+func searchValues(f model.KataFilter) url.Values {
+	values := url.Values{}
+	if f.Query != "" {
+		values.Set("q", f.Query)
+	}
+	ranks := slices.Clone(f.Ranks)
+	slices.Sort(ranks)
+	for _, r := range ranks {
+		values.Add("r[]", strconv.Itoa(r))
+	}
+	if len(f.Tags) > 0 {
+		tags := slices.Clone(f.Tags)
+		slices.Sort(tags)
+		values.Set("tags", strings.Join(tags, ","))
+	}
+	switch f.Status {
+	case "approved":
+		values.Set("beta", "false")
+	case "beta":
+		values.Set("beta", "true")
+	}
+	if f.OrderBy != "" {
+		values.Set("order_by", f.OrderBy)
+	}
+	return values
+}
+
+// searchPageURL строит адрес страницы поиска. Язык передается сегментом пути,
+// как на сайте: /kata/search/go?r[]=-6
+func (c *Client) searchPageURL(f model.KataFilter, page int) string {
+	u := c.searchURL
+	if f.Language != "" {
+		u += "/" + url.PathEscape(f.Language)
+	}
+	values := searchValues(f)
+	if page > 0 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if len(values) == 0 {
+		return u
+	}
+	return u + "?" + values.Encode()
+}
+
+// SearchKatas обходит страницы поиска задач с фильтрами, пока не наберет limit
+// задач, не закончатся результаты или не будет пройдено maxSearchPages страниц
+func (c *Client) SearchKatas(ctx context.Context, filter model.KataFilter, limit int) ([]model.KataSummary, error) {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "text/html")
+
+	seen := make(map[string]struct{})
+	result := make([]model.KataSummary, 0, limit)
+
+	for page := 0; page < maxSearchPages && len(result) < limit; page++ {
+		res, err := c.get(ctx, EndpointSearch, c.searchPageURL(filter, page), header)
+		if err != nil {
+			if page > 0 && len(result) > 0 {
+				// Уже собранные страницы полезнее, чем ошибка
+				c.logger.Warn("kata search stopped early", "page", page, "error", err)
+				break
+			}
+			return nil, fmt.Errorf("search request failed: %w", err)
+		}
+
+		added := 0
+		for _, kata := range parseSearchPage(string(res.body)) {
+			if _, ok := seen[kata.ID]; ok {
+				continue
+			}
+			seen[kata.ID] = struct{}{}
+			result = append(result, kata)
+			added++
+			if len(result) == limit {
+				break
+			}
+		}
+		if added == 0 {
+			break // Страницы закончились или сайт повторяет последнюю
+		}
+	}
+
+	return result, nil
+}
+
+// parseSearchPage разбирает HTML страницы поиска. Каждая задача - блок
+// list-item-kata; если разметка изменилась и блоков нет, берутся хотя бы ID из ссылок
+func parseSearchPage(html string) []model.KataSummary {
+	starts := kataItemRe.FindAllStringIndex(html, -1)
+	if len(starts) == 0 {
+		return parseSearchIDs(html)
+	}
+
+	result := make([]model.KataSummary, 0, len(starts))
+	for i, loc := range starts {
+		end := len(html)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		if kata, ok := parseSearchItem(html[loc[0]:end]); ok {
+			result = append(result, kata)
+		}
+	}
+	return result
+}
+
+func parseSearchItem(block string) (model.KataSummary, bool) {
+	var kata model.KataSummary
+
+	if m := kataIDAttrRe.FindStringSubmatch(block); m != nil {
+		kata.ID = m[1]
+	} else if m := kataLinkRe.FindStringSubmatch(block); m != nil {
+		kata.ID = m[1]
+	} else {
+		return kata, false
+	}
+
+	if m := kataNameRe.FindStringSubmatch(block); m != nil {
+		kata.Name = html.UnescapeString(m[1])
+	}
+
+	if m := kataRankRe.FindStringSubmatch(block); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		rank := n
+		if m[2] == "kyu" {
+			rank = -n
+		}
+		kata.Rank = &rank
+		kata.RankName = m[1] + " " + m[2]
+	}
+
+	kata.Languages = []string{}
+	for _, m := range kataLanguageRe.FindAllStringSubmatch(block, -1) {
+		if !slices.Contains(kata.Languages, m[1]) {
+			kata.Languages = append(kata.Languages, m[1])
+		}
+	}
+
+	return kata, true
+}
+
+func parseSearchIDs(html string) []model.KataSummary {
+	matches := kataLinkRe.FindAllStringSubmatch(html, -1)
+
+	seen := make(map[string]struct{})
+	result := make([]model.KataSummary, 0, len(matches))
+	for _, m := range matches {
+		if _, ok := seen[m[1]]; ok {
+			continue
+		}
+		seen[m[1]] = struct{}{}
+		result = append(result, model.KataSummary{ID: m[1], Languages: []string{}})
+	}
+	return result
+}
+
+// scrapeKataList собирает ID задач для буфера по фильтру
+func (c *Client) scrapeKataList(ctx context.Context, filter model.KataFilter) ([]string, error) {
+	katas, err := c.SearchKatas(ctx, filter, defaultSearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("scrape request failed: %w", err)
+	}
+
+	if len(katas) == 0 {
+		return nil, errors.New("no kata IDs found in HTML")
+	}
+
+	// Без фильтров поиск всегда возвращает много задач, иначе сломался разбор
+	if FilterKey(filter) == "" && len(katas) < 5 {
+		return nil, fmt.Errorf("found too few katas (%d), possible parsing error", len(katas))
+	}
+
+	ids := make([]string, 0, len(katas))
+	for _, kata := range katas {
+		ids = append(ids, kata.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,97 @@
+package codewars
+
+import (
+	"SolverAPI/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestParseSearchPage(t *testing.T) {
+	const (
+		idA = "5277c8a221e209d3f6000b56"
+		idB = "52742f58faf5485cae000b9a"
+	)
+
+	rank := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		html string
+		want []model.KataSummary
+	}{
+		{
+			name: "kyu item with escaped name and languages",
+			html: `<div class="list-item-kata bg-ui-section" id="` + idA + `" data-id="` + idA + `">` +
+				`<div class="small-hex"><div class="inner-small-hex"><span>6 kyu</span></div></div>` +
+				`<a href="/kata/` + idA + `/train/python">Valid &amp; Braces</a>` +
+				`<div class="languages"><a href="/kata/` + idA + `/train/python" data-language="python"></a>` +
+				`<a href="/kata/` + idA + `/train/go" data-language="go"></a></div></div>`,
+			want: []model.KataSummary{
+				{ID: idA, Name: "Valid & Braces", Rank: rank(-6), RankName: "6 kyu", Languages: []string{"python", "go"}},
+			},
+		},
+		{
+			name: "dan rank is positive",
+			html: `<div class="list-item-kata" data-id="` + idA + `"><span> 2 dan </span>` +
+				`<a href="/kata/` + idA + `">  Hard one  </a></div>`,
+			want: []model.KataSummary{
+				{ID: idA, Name: "Hard one", Rank: rank(2), RankName: "2 dan", Languages: []string{}},
+			},
+		},
+		{
+			name: "beta item has no rank",
+			html: `<div class="list-item-kata" data-id="` + idA + `">` +
+				`<a href="/kata/` + idA + `/train/rust">Beta kata</a></div>`,
+			want: []model.KataSummary{
+				{ID: idA, Name: "Beta kata", Languages: []string{"rust"}},
+			},
+		},
+		{
+			name: "several items keep page order",
+			html: `<div class="list-item-kata" data-id="` + idB + `"><a href="/kata/` + idB + `">Second</a></div>` +
+				`<div class="list-item-kata" data-id="` + idA + `"><a href="/kata/` + idA + `">First</a></div>`,
+			want: []model.KataSummary{
+				{ID: idB, Name: "Second", Languages: []string{}},
+				{ID: idA, Name: "First", Languages: []string{}},
+			},
+		},
+		{
+			name: "item id falls back to link",
+			html: `<div class="list-item-kata"><a href="/kata/` + idB + `/train/go">From link</a></div>`,
+			want: []model.KataSummary{
+				{ID: idB, Name: "From link", Languages: []string{"go"}},
+			},
+		},
+		{
+			name: "item without id is skipped",
+			html: `<div class="list-item-kata"><a href="/about">About</a></div>` +
+				`<div class="list-item-kata" data-id="` + idA + `"><a href="/kata/` + idA + `">Kept</a></div>`,
+			want: []model.KataSummary{
+				{ID: idA, Name: "Kept", Languages: []string{}},
+			},
+		},
+		{
+			name: "unknown markup falls back to deduplicated links",
+			html: `<ul><li><a href="/kata/` + idA + `">A</a><a href="/kata/` + idA + `/train/go">A</a></li>` +
+				`<li><a href="/kata/` + idB + `">B</a></li></ul>`,
+			want: []model.KataSummary{
+				{ID: idA, Languages: []string{}},
+				{ID: idB, Languages: []string{}},
+			},
+		},
+		{
+			name: "empty page",
+			html: `<div class="items-list"></div>`,
+			want: []model.KataSummary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSearchPage(tt.html)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearchPage =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package codewars
+
+// Stats - метрики клиента Codewars
+type Stats struct {
+	RateLimiter LimiterStats               `json:"rate_limiter"`
+	Cache       CacheStats                 `json:"cache"`
+	Coalescing  CoalesceStats              `json:"coalescing"`
+	Breaker     BreakerStats               `json:"breaker"`
+	Endpoints   map[Endpoint]EndpointStats `json:"endpoints"`
+}
+
+// Stats возвращает текущие метрики клиента
+func (c *Client) Stats() Stats {
+	return Stats{
+		RateLimiter: c.limiter.Stats(),
+		Cache:       c.cache.Stats(),
+		Coalescing:  c.flights.Stats(),
+		Breaker:     c.breaker.Stats(),
+		Endpoints:   c.metrics.Stats(),
+	}
+}
+
+// BreakerStats возвращает состояние circuit breaker
+func (c *Client) BreakerStats() BreakerStats {
+	return c.breaker.Stats()
+}